@@ -0,0 +1,68 @@
+package gitignore
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Builder accumulates patterns from several named sources and compiles them
+// into one matcher, ordering sources by priority. Construct with NewBuilder
+// or NewBuilderOptions.
+//
+// Sources with a higher priority take precedence: their patterns are placed
+// after those of lower-priority sources, so they win under last-match-wins.
+// Sources with equal priority keep the order in which they were added.
+// Mirroring Git, a typical setup gives core.excludesFile the lowest priority,
+// then .git/info/exclude, then .gitignore files.
+type Builder struct {
+	// options used to compile every source
+	opts Options
+	// sources in the order they were added
+	sources []builderSource
+}
+
+// builderSource is one named set of lines added to a Builder.
+type builderSource struct {
+	// name reported as Match.Source
+	name string
+	// precedence, higher wins
+	priority int
+	// raw .gitignore lines
+	lines []string
+}
+
+// NewBuilder returns an empty Builder using default Options.
+func NewBuilder() *Builder {
+	return NewBuilderOptions(Options{})
+}
+
+// NewBuilderOptions returns an empty Builder using opt for every source.
+func NewBuilderOptions(opt Options) *Builder {
+	return &Builder{opts: opt}
+}
+
+// AddSource adds lines from the source called name with the given priority.
+// The name and each pattern's 1-based line number are reported by Match.
+func (b *Builder) AddSource(name string, priority int, lines ...string) *Builder {
+	b.sources = append(b.sources, builderSource{name: name, priority: priority, lines: lines})
+
+	return b
+}
+
+// Build compiles all sources into a new matcher. The Builder can keep being
+// used afterwards; later Build calls include sources added in between.
+func (b *Builder) Build() *GitIgnore {
+	sources := slices.Clone(b.sources)
+
+	slices.SortStableFunc(sources, func(a, b builderSource) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	g := &GitIgnore{opts: b.opts}
+
+	for _, s := range sources {
+		g.appendSource(s.name, s.lines)
+	}
+
+	return g
+}
@@ -0,0 +1,66 @@
+package gitignore_test
+
+import (
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	// Added out of order on purpose: priority, not insertion, decides precedence.
+	g := gitignore.NewBuilder().
+		AddSource(".gitignore", 2, "# project", "!keep.log", "build/").
+		AddSource("global", 0, "*.log", "*.tmp").
+		AddSource(".git/info/exclude", 1, "*.tmp", "!important.tmp").
+		Build()
+
+	want := []string{"*.log", "*.tmp", "*.tmp", "!important.tmp", "!keep.log", "build/"}
+	if got := g.Patterns(); !slices.Equal(got, want) {
+		t.Fatalf("Patterns() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		path   string
+		isDir  bool
+		want   bool
+		source string
+		line   int
+	}{
+		{path: "app.log", want: true, source: "global", line: 1},
+		{path: "keep.log", want: false, source: ".gitignore", line: 2},
+		{path: "x.tmp", want: true, source: ".git/info/exclude", line: 1},
+		{path: "important.tmp", want: false, source: ".git/info/exclude", line: 2},
+		{path: "build/out.bin", want: true, source: ".gitignore", line: 3},
+		{path: "main.go", want: false},
+	}
+
+	for _, tc := range tests {
+		m := g.Match(tc.path, tc.isDir)
+		if m.Ignored != tc.want || m.Source != tc.source || m.Line != tc.line {
+			t.Errorf("Match(%q) = %+v, want ignored %v from %s:%d", tc.path, m, tc.want, tc.source, tc.line)
+		}
+	}
+}
+
+func TestAppendLineNumbers(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("# comment", "", "*.log")
+	g.Append("!keep.log")
+
+	if m := g.Match("a.log", false); m.Source != "" || m.Line != 3 {
+		t.Errorf("Match(a.log) = %+v, want line 3", m)
+	}
+
+	if m := g.Match("keep.log", false); m.Line != 1 {
+		t.Errorf("Match(keep.log) = %+v, want line 1 of the appended lines", m)
+	}
+
+	restored := gitignore.FromCompiled(gitignore.Options{}, g.Export())
+	if !restored.Equal(g) {
+		t.Error("FromCompiled(Export()) lost line numbers")
+	}
+}
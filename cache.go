@@ -0,0 +1,101 @@
+package gitignore
+
+import (
+	"container/list"
+	"sync"
+)
+
+// WithCache returns a matcher sharing g's compiled patterns and options whose
+// Ignored results are kept in a least-recently-used cache of up to size
+// entries, for callers that query the same paths repeatedly. The cache is
+// safe for concurrent use and is cleared whenever patterns are appended to
+// the returned matcher. A size of zero or less returns a matcher without a
+// cache. Only Ignored consults the cache; results are always identical to
+// those of g.
+func (g *GitIgnore) WithCache(size int) *GitIgnore {
+	v := g.clone()
+
+	if size > 0 {
+		v.cache = newLRU(size)
+	}
+
+	return v
+}
+
+// cacheKey identifies a cached Ignored query.
+type cacheKey struct {
+	// the path as given
+	pathname string
+	// whether the path was queried as a directory
+	isDir bool
+}
+
+// cacheEntry is an element of the lru list.
+type cacheEntry struct {
+	// the query
+	key cacheKey
+	// its result
+	ignored bool
+}
+
+// lru is a bounded, mutex-guarded least-recently-used cache of Ignored results.
+type lru struct {
+	// guards all fields below
+	mu sync.Mutex
+	// maximum number of entries
+	size int
+	// entries, most recently used first
+	order *list.List
+	// entries by key
+	items map[cacheKey]*list.Element
+}
+
+// newLRU returns an empty cache holding up to size entries.
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), items: make(map[cacheKey]*list.Element, size)}
+}
+
+// get returns the cached result for key, marking it most recently used.
+func (c *lru) get(key cacheKey) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return false, false
+	}
+
+	c.order.MoveToFront(e)
+
+	return e.Value.(*cacheEntry).ignored, true //nolint:forcetypeassert	// only *cacheEntry is stored
+}
+
+// put stores the result for key, evicting the least recently used entry when full.
+func (c *lru) put(key cacheKey, ignored bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*cacheEntry).ignored = ignored //nolint:forcetypeassert	// only *cacheEntry is stored
+		c.order.MoveToFront(e)
+
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key) //nolint:forcetypeassert	// only *cacheEntry is stored
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, ignored: ignored})
+}
+
+// clear removes all entries.
+func (c *lru) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.items)
+}
@@ -0,0 +1,70 @@
+package gitignore_test
+
+import (
+	"sync"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestWithCache(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/", "node_modules", "/*/tmp/")
+
+	for _, size := range []int{0, 1, 3, 100} {
+		cached := g.WithCache(size)
+
+		// Query every path several times so small caches evict and refill.
+		for range 3 {
+			for _, tc := range fastPathPaths {
+				want := g.Ignored(tc.path, tc.isDir)
+				if got := cached.Ignored(tc.path, tc.isDir); got != want {
+					t.Errorf("size %d: Ignored(%q, %v) = %v, uncached %v", size, tc.path, tc.isDir, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestWithCacheAppend(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log")
+	cached := g.WithCache(10)
+
+	if cached.Ignored("main.go", false) {
+		t.Fatal("main.go ignored before Append")
+	}
+
+	cached.Append("*.go")
+
+	if !cached.Ignored("main.go", false) {
+		t.Error("stale cached result after Append")
+	}
+
+	if g.Ignored("main.go", false) {
+		t.Error("Append on the cached matcher affected the original")
+	}
+}
+
+func TestWithCacheConcurrent(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "!build/keep/")
+	cached := g.WithCache(4)
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			for _, tc := range fastPathPaths {
+				if got, want := cached.Ignored(tc.path, tc.isDir), g.Ignored(tc.path, tc.isDir); got != want {
+					t.Errorf("Ignored(%q, %v) = %v, uncached %v", tc.path, tc.isDir, got, want)
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,167 @@
+package gitignore
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/idelchi/go-gitignore/wildmatch"
+)
+
+// CompiledPattern is a serializable snapshot of a single compiled pattern.
+// It is produced by Export and consumed by FromCompiled; its fields mirror
+// the matcher's internal representation and should be treated as opaque.
+type CompiledPattern struct {
+	// Original is the pattern line as given.
+	Original string `json:"original"`
+	// Pattern is the normalized pattern used for matching.
+	Pattern string `json:"pattern"`
+	// NoWildcardLen is the number of leading literal bytes in Pattern.
+	NoWildcardLen int `json:"noWildcardLen"`
+	// Flags is the internal bitmask of pattern traits.
+	Flags uint16 `json:"flags"`
+	// Slashes is the number of '/' a matching path must contain, or -1.
+	Slashes int `json:"slashes"`
+	// Source and Line record where the pattern came from.
+	Source string `json:"source,omitempty"`
+	Line   int    `json:"line"`
+}
+
+// Export returns the compiled patterns in order, suitable for caching with
+// gob or JSON and later rebuilding the matcher with FromCompiled.
+func (g *GitIgnore) Export() []CompiledPattern {
+	out := make([]CompiledPattern, len(g.patterns))
+
+	for i, p := range g.patterns {
+		out[i] = CompiledPattern{
+			Original:      p.original,
+			Pattern:       p.pattern,
+			NoWildcardLen: p.nowildcardlen,
+			Flags:         uint16(p.flags),
+			Slashes:       p.slashes,
+			Source:        p.source,
+			Line:          p.line,
+		}
+	}
+
+	return out
+}
+
+// FromCompiled rebuilds a matcher from patterns returned by Export, skipping
+// parsing entirely. Parse-time options in opt have no effect.
+func FromCompiled(opt Options, ps []CompiledPattern) *GitIgnore {
+	g := &GitIgnore{patterns: make([]pattern, 0, len(ps)), opts: opt}
+
+	for _, cp := range ps {
+		g.add(pattern{
+			original:      cp.Original,
+			pattern:       cp.Pattern,
+			patternlen:    len(cp.Pattern),
+			nowildcardlen: cp.NoWildcardLen,
+			flags:         patternFlag(cp.Flags),
+			slashes:       cp.Slashes,
+			source:        cp.Source,
+			line:          cp.Line,
+			minlen:        wildmatch.MinLen(strings.TrimPrefix(cp.Pattern, "/")),
+		})
+	}
+
+	return g
+}
+
+// WithOptions returns a matcher sharing g's compiled patterns but matching
+// with opt, without re-parsing anything. Only match-time options take effect;
+// the parse-time options CommentPrefix, NegationPrefix, NormalizeUnicode,
+// NormalizeSlashes, IgnoreNegations, NegationsAsPatterns and MaxPatternLength
+// keep the values g was compiled with.
+// Appending to either matcher never affects the other, and the result has no
+// cache (see WithCache).
+func (g *GitIgnore) WithOptions(opt Options) *GitIgnore {
+	opt.CommentPrefix = g.opts.CommentPrefix
+	opt.NegationPrefix = g.opts.NegationPrefix
+	opt.NormalizeUnicode = g.opts.NormalizeUnicode
+	opt.NormalizeSlashes = g.opts.NormalizeSlashes
+	opt.IgnoreNegations = g.opts.IgnoreNegations
+	opt.NegationsAsPatterns = g.opts.NegationsAsPatterns
+	opt.MaxPatternLength = g.opts.MaxPatternLength
+
+	v := g.clone()
+	v.opts = opt
+
+	return v
+}
+
+// clone returns a matcher sharing g's compiled patterns and options, without
+// any cache. Appending to either never affects the other.
+func (g *GitIgnore) clone() *GitIgnore {
+	return &GitIgnore{
+		patterns:    slices.Clip(g.patterns),
+		opts:        g.opts,
+		nonBasename: g.nonBasename,
+		globstars:   g.globstars,
+	}
+}
+
+// Equal reports whether g and other hold identical compiled patterns in the
+// same order and equivalent options. Function-valued options are compared by
+// presence only, and where patterns came from (Match.Source and Match.Line)
+// is not compared.
+func (g *GitIgnore) Equal(other *GitIgnore) bool {
+	if g == nil || other == nil {
+		return g == other
+	}
+
+	if len(g.patterns) != len(other.patterns) || !g.opts.equal(other.opts) {
+		return false
+	}
+
+	for i := range g.patterns {
+		a, b := g.patterns[i], other.patterns[i]
+		a.source, a.line = b.source, b.line
+
+		if a != b {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WriteTo writes the patterns of g as .gitignore lines, one original pattern
+// per line in order, implementing io.WriterTo. Comments and blank lines were
+// dropped when compiling and are not written. Parsing the output with the
+// same parse-time options yields a matcher Equal to g.
+func (g *GitIgnore) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+
+	for _, p := range g.patterns {
+		n, err := io.WriteString(w, p.original+"\n")
+		written += int64(n)
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// equal compares options, treating function-valued fields as equal when both
+// are set or both are nil.
+func (o Options) equal(other Options) bool {
+	return o.CaseFold == other.CaseFold &&
+		(o.CaseFoldFunc == nil) == (other.CaseFoldFunc == nil) &&
+		o.CommentPrefix == other.CommentPrefix &&
+		o.NegationPrefix == other.NegationPrefix &&
+		o.Strict == other.Strict &&
+		o.NormalizeUnicode == other.NormalizeUnicode &&
+		o.AllowReincludeUnderExcluded == other.AllowReincludeUnderExcluded &&
+		(o.PathTransform == nil) == (other.PathTransform == nil) &&
+		o.InferDirFromSlash == other.InferDirFromSlash &&
+		o.MaxPatternLength == other.MaxPatternLength &&
+		o.MaxStarstar == other.MaxStarstar &&
+		o.NormalizeSlashes == other.NormalizeSlashes &&
+		o.IgnoreNegations == other.IgnoreNegations &&
+		o.NegationsAsPatterns == other.NegationsAsPatterns &&
+		o.Intern == other.Intern
+}
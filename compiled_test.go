@@ -0,0 +1,115 @@
+package gitignore_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestExportFromCompiled(t *testing.T) {
+	t.Parallel()
+
+	lines := getRealWorldGitignore()
+	g := gitignore.New(lines...)
+
+	data, err := json.Marshal(g.Export())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var ps []gitignore.CompiledPattern
+	if err := json.Unmarshal(data, &ps); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	restored := gitignore.FromCompiled(gitignore.Options{}, ps)
+
+	if !restored.Equal(g) {
+		t.Fatal("restored matcher is not Equal to the original")
+	}
+
+	for _, tc := range fastPathPaths {
+		if got, want := restored.Match(tc.path, tc.isDir), g.Match(tc.path, tc.isDir); got != want {
+			t.Errorf("Match(%q, %v) = %+v, want %+v", tc.path, tc.isDir, got, want)
+		}
+	}
+
+	if restored.Equal(gitignore.New(lines[:len(lines)-2]...)) {
+		t.Error("matchers with different patterns reported Equal")
+	}
+
+	if restored.Equal(gitignore.NewOptions(gitignore.Options{CaseFold: true}, lines...)) {
+		t.Error("matchers with different options reported Equal")
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.LOG", "Build/", "!KEEP.log")
+	folded := g.WithOptions(gitignore.Options{CaseFold: true})
+
+	tests := []struct {
+		path        string
+		isDir       bool
+		plain, fold bool
+	}{
+		{path: "app.LOG", plain: true, fold: true},
+		{path: "app.log", plain: false, fold: true},
+		{path: "build", isDir: true, plain: false, fold: true},
+		{path: "keep.log", plain: false, fold: false},
+		{path: "keep.LOG", plain: true, fold: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.Ignored(tc.path, tc.isDir); got != tc.plain {
+			t.Errorf("original: Ignored(%q) = %v, want %v", tc.path, got, tc.plain)
+		}
+
+		if got := folded.Ignored(tc.path, tc.isDir); got != tc.fold {
+			t.Errorf("view: Ignored(%q) = %v, want %v", tc.path, got, tc.fold)
+		}
+	}
+
+	// Appending to one view must not leak into the other.
+	folded.Append("*.tmp")
+	g.Append("*.bak")
+
+	if g.Ignored("a.tmp", false) || folded.Ignored("a.bak", false) {
+		t.Error("Append on one view affected the other")
+	}
+
+	if !folded.Ignored("A.TMP", false) || !g.Ignored("a.bak", false) {
+		t.Error("Append on a view was lost")
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("# header", "*.log", "", "!keep.log", "name\\ \\ ", "build/  ")
+
+	var buf strings.Builder
+
+	n, err := g.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+
+	want := "*.log\n!keep.log\nname\\ \\ \nbuild/  \n"
+	if got := buf.String(); got != want || n != int64(len(want)) {
+		t.Errorf("WriteTo() = (%d, %q), want (%d, %q)", n, got, len(want), want)
+	}
+
+	restored, err := gitignore.NewFromReader(gitignore.Options{}, strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("NewFromReader() error: %v", err)
+	}
+
+	// Line numbers differ after dropping the comment, but Equal ignores them.
+	if !restored.Equal(g) {
+		t.Error("re-parsed matcher is not Equal to the original")
+	}
+}
@@ -0,0 +1,106 @@
+package gitignore
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Conflict describes two patterns whose combination is likely unintended.
+type Conflict struct {
+	// Earlier and Later are indexes into Patterns, with Earlier < Later.
+	Earlier, Later int
+	// Description explains the interaction.
+	Description string
+}
+
+// String formats the conflict as "patterns I and J: description".
+func (c Conflict) String() string {
+	return fmt.Sprintf("patterns %d and %d: %s", c.Earlier, c.Later, c.Description)
+}
+
+// Conflicts reports pattern pairs that are likely mistakes in a hand-edited
+// file. It is conservative and only reports two cases:
+//
+//   - a pattern followed by its exact inverse ("foo/*.log" then "!foo/*.log"),
+//     so the earlier one never decides any path;
+//   - a negation whose literal leading directory is itself ignored
+//     ("build/" and "!build/keep.txt"), so it can never re-include anything.
+//     Under Options.AllowReincludeUnderExcluded such a negation does apply,
+//     and this case is not reported.
+//
+// Conflicts is static analysis only and does not change matching.
+func (g *GitIgnore) Conflicts() []Conflict {
+	var out []Conflict
+
+	for i := range g.patterns {
+		for j := i + 1; j < len(g.patterns); j++ {
+			if inverse(&g.patterns[i], &g.patterns[j]) {
+				out = append(out, Conflict{
+					Earlier:     i,
+					Later:       j,
+					Description: fmt.Sprintf("%q overrides every path %q matches", g.patterns[j].original, g.patterns[i].original),
+				})
+
+				break
+			}
+		}
+	}
+
+	for j := range g.patterns {
+		p := &g.patterns[j]
+
+		if p.flags&flagNegative == 0 || g.opts.AllowReincludeUnderExcluded {
+			continue
+		}
+
+		dir := literalDir(p.pattern)
+		if dir == "" {
+			continue
+		}
+
+		d := g.decide(g.opts.keepDir(dir, true), true)
+		if !d.match.Ignored {
+			continue
+		}
+
+		out = append(out, Conflict{
+			Earlier: min(d.index, j),
+			Later:   max(d.index, j),
+			Description: fmt.Sprintf("%q cannot re-include anything: its directory %q is excluded by %q",
+				p.original, dir, g.patterns[d.index].original),
+		})
+	}
+
+	slices.SortFunc(out, func(a, b Conflict) int {
+		return cmp.Or(cmp.Compare(a.Earlier, b.Earlier), cmp.Compare(a.Later, b.Later))
+	})
+
+	return out
+}
+
+// inverse reports whether a and b are the same compiled pattern with opposite signs.
+func inverse(a, b *pattern) bool {
+	return a.pattern == b.pattern && a.flags^b.flags == flagNegative
+}
+
+// literalDir returns the leading directory components of a path pattern that
+// contain no wildcards, or "" if there are none. The final component is never
+// included, so every path the pattern matches lies below the result.
+func literalDir(pattern string) string {
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	end := 0
+
+	for {
+		next := strings.IndexByte(pattern[end:], '/')
+		if next < 0 || !noWildcard(pattern[end:end+next]) {
+			break
+		}
+
+		end += next + 1
+	}
+
+	return strings.TrimSuffix(pattern[:end], "/")
+}
@@ -0,0 +1,57 @@
+package gitignore_test
+
+import (
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestConflicts(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New(
+		"foo/*.log",        // 0
+		"build/",           // 1
+		"!foo/*.log",       // 2: inverts 0
+		"!build/keep.txt",  // 3: build is excluded by 1
+		"*.tmp",            // 4
+		"!*.tmp/",          // 5: dir-only, not an exact inverse of 4
+		"docs/**",          // 6
+		"!docs/**/",        // 7
+		"!docs/api/x.md",   // 8: docs/api is re-included by 7
+		"!vendor/*/go.mod", // 9: vendor is not ignored
+		"/out",             // 10
+		"!/out/a/b",        // 11: out excluded by 10
+	)
+
+	want := []gitignore.Conflict{
+		{Earlier: 0, Later: 2},
+		{Earlier: 1, Later: 3},
+		{Earlier: 10, Later: 11},
+	}
+
+	got := g.Conflicts()
+	if len(got) != len(want) {
+		t.Fatalf("Conflicts() = %v, want %d entries", got, len(want))
+	}
+
+	for i := range want {
+		if got[i].Earlier != want[i].Earlier || got[i].Later != want[i].Later {
+			t.Errorf("conflict %d = %v, want pair (%d, %d)", i, got[i], want[i].Earlier, want[i].Later)
+		}
+
+		if got[i].Description == "" {
+			t.Errorf("conflict %d has no description", i)
+		}
+	}
+
+	if got := gitignore.New("*.log", "!keep.log", "build/").Conflicts(); len(got) != 0 {
+		t.Errorf("Conflicts() on a clean file = %v, want none", got)
+	}
+
+	// Negations below an excluded directory apply when re-including is allowed.
+	lenient := gitignore.NewOptions(gitignore.Options{AllowReincludeUnderExcluded: true}, "a/", "!a/b/", "x", "!x")
+	if got := lenient.Conflicts(); len(got) != 1 || got[0].Earlier != 2 || got[0].Later != 3 {
+		t.Errorf("AllowReincludeUnderExcluded: Conflicts() = %v, want only the inverse pair (2, 3)", got)
+	}
+}
@@ -0,0 +1,192 @@
+package gitignore_test
+
+import (
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+// fastPathPaths is a mix of paths used to compare fast paths against Match.
+//
+//nolint:gochecknoglobals	// shared read-only fixture
+var fastPathPaths = []struct {
+	path  string
+	isDir bool
+}{
+	{"app.log", false},
+	{"src/app.log", false},
+	{"node_modules", true},
+	{"node_modules", false},
+	{"a/node_modules/pkg/index.js", false},
+	{"build", true},
+	{"build", false},
+	{"x/build/y", false},
+	{"x/build/y", true},
+	{"src/main.go", false},
+	{"./src//main.go", false},
+	{".", true},
+	{"..", true},
+	{"../a.log", false},
+	{"/abs/app.log", false},
+	{"", false},
+	{".cache", true},
+	{"deep/a/b/c/d/e/f/.DS_Store", false},
+	{"a/keep.log", false},
+	{"x/py_cache", true},
+}
+
+func TestIgnoredBasenameFastPath(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "node_modules", "build/", ".DS_Store", ".*", "*.py[cod]")
+
+	for _, tc := range fastPathPaths {
+		want := g.Match(tc.path, tc.isDir).Ignored
+		if got := g.Ignored(tc.path, tc.isDir); got != want {
+			t.Errorf("Ignored(%q, %v) = %v, Match reports %v", tc.path, tc.isDir, got, want)
+		}
+	}
+}
+
+func TestGlobstarSuffixEquivalence(t *testing.T) {
+	t.Parallel()
+
+	globstar := gitignore.New("**/*.log", "!**/*keep.log", "**/*_cache/")
+	basename := gitignore.New("*.log", "!*keep.log", "*_cache/")
+
+	for _, tc := range fastPathPaths {
+		want := basename.Match(tc.path, tc.isDir).Ignored
+		if got := globstar.Ignored(tc.path, tc.isDir); got != want {
+			t.Errorf("Ignored(%q, %v) = %v, basename form reports %v", tc.path, tc.isDir, got, want)
+		}
+	}
+}
+
+func TestIgnoredDirEquivalence(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"*.log", "build/", "x/*", "!x/build/", "node_modules", "!a/node_modules/", "/*", "!/src/", "!/x/"}
+
+	matchers := map[string]*gitignore.GitIgnore{
+		"basenames": gitignore.New("*.log", "node_modules", "build/", ".*"),
+		"mixed":     gitignore.New(lines...),
+		"reinclude": gitignore.NewOptions(gitignore.Options{AllowReincludeUnderExcluded: true}, lines...),
+	}
+
+	for name, g := range matchers {
+		for _, tc := range fastPathPaths {
+			want := g.Ignored(tc.path, true)
+			if got := g.IgnoredDir(tc.path); got != want {
+				t.Errorf("%s: IgnoredDir(%q) = %v, Ignored reports %v", name, tc.path, got, want)
+			}
+		}
+	}
+}
+
+func TestTopLevelIgnored(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("/*", "!/src/", "!README.md", "*.log", "!keep.log", "build/", "/docs/*")
+
+	names := []string{
+		"src", "README.md", "Makefile", "app.log", "keep.log", "build", "build/", "docs", "./x", "a/b", ".", "..", "",
+	}
+
+	isDir := func(name string) bool { return name == "src" || name == "build" || name == "docs" }
+
+	got := g.TopLevelIgnored(names, isDir)
+
+	var want []string
+
+	for _, name := range names {
+		if g.Ignored(name, isDir(name)) {
+			want = append(want, name)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("TopLevelIgnored() = %q, Ignored reports %q", got, want)
+	}
+
+	// Without isDir, a trailing '/' marks directories.
+	if got := g.TopLevelIgnored([]string{"build/", "src/", "src"}, nil); !slices.Equal(got, []string{"build/", "src"}) {
+		t.Errorf("TopLevelIgnored(nil) = %q, want [build/ src]", got)
+	}
+}
+
+func TestContentsFastPath(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"vendor/**", "/build/**", "a/b/**/", "!vendor/keep/**", "src/*/**"}
+
+	// A CaseFoldFunc that never folds forces every pattern through wildmatch.
+	slow := gitignore.NewOptions(gitignore.Options{CaseFoldFunc: func(string) bool { return false }}, lines...)
+	fast := gitignore.New(lines...)
+
+	paths := []struct {
+		path  string
+		isDir bool
+	}{
+		{"vendor", true},
+		{"vendor/lib/file.go", false},
+		{"vendor/keep/x", false},
+		{"vendorx/file.go", false},
+		{"x/vendor/file.go", false},
+		{"build", true},
+		{"build/out.bin", false},
+		{"a/b", true},
+		{"a/b/c", false},
+		{"a/b/c", true},
+		{"src/x/y.go", false},
+	}
+
+	for _, tc := range paths {
+		want := slow.Match(tc.path, tc.isDir)
+		if got := fast.Match(tc.path, tc.isDir); got != want {
+			t.Errorf("Match(%q, %v) = %+v, wildmatch reports %+v", tc.path, tc.isDir, got, want)
+		}
+	}
+
+	folded := gitignore.NewOptions(gitignore.Options{CaseFold: true}, "Vendor/**")
+	if !folded.Ignored("VENDOR/lib.go", false) || folded.Ignored("vendor", true) {
+		t.Error("CaseFold: literal/** fast path disagrees with git")
+	}
+}
+
+func TestIgnoredEither(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"*.log", "build/", "!build/keep/", "node_modules", "x/*", "!x/build/", "/*/tmp/", "!keep.log"}
+
+	matchers := map[string]*gitignore.GitIgnore{
+		"basenames": gitignore.New("*.log", "node_modules", "build/", ".*"),
+		"mixed":     gitignore.New(lines...),
+		"reinclude": gitignore.NewOptions(gitignore.Options{AllowReincludeUnderExcluded: true}, lines...),
+		"infer":     gitignore.NewOptions(gitignore.Options{InferDirFromSlash: true}, lines...),
+	}
+
+	paths := []string{"build/", "x/build/keep.log", "a/tmp", "a/tmp/keep.log"}
+	for _, tc := range fastPathPaths {
+		paths = append(paths, tc.path)
+	}
+
+	for name, g := range matchers {
+		for _, p := range paths {
+			file, dir := g.IgnoredEither(p)
+			if want := g.Ignored(p, false); file != want {
+				t.Errorf("%s: IgnoredEither(%q) file = %v, Ignored reports %v", name, p, file, want)
+			}
+
+			if want := g.Ignored(p, true); name != "infer" && dir != want {
+				t.Errorf("%s: IgnoredEither(%q) dir = %v, Ignored reports %v", name, p, dir, want)
+			}
+
+			// Under InferDirFromSlash, Ignored takes p without a slash for a
+			// file, while IgnoredDir keeps it a directory.
+			if want := g.IgnoredDir(p); name == "infer" && dir != want {
+				t.Errorf("%s: IgnoredEither(%q) dir = %v, IgnoredDir reports %v", name, p, dir, want)
+			}
+		}
+	}
+}
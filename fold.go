@@ -0,0 +1,49 @@
+package gitignore
+
+import "strings"
+
+// LowerFold returns a matcher equivalent to g with CaseFold set, for paths
+// already folded with FoldPath. Its patterns are lowered once here, so the
+// literal comparisons done for every query skip per-byte folding. Patterns
+// with a bracket expression or an escape keep folding each byte, as lowering
+// them would change what they match: a range such as "[Z-a]" covers more than
+// "[z-a]", and CaseFold never folds an escaped letter, so "\R" matches
+// nothing while "\r" matches "r". CaseFoldFunc is dropped, and paths
+// that were not passed through FoldPath give unspecified results. Patterns
+// and Match.Pattern keep reporting the patterns as given.
+func (g *GitIgnore) LowerFold() *GitIgnore {
+	v := g.clone()
+	v.opts.CaseFold = false
+	v.opts.CaseFoldFunc = nil
+	v.patterns = make([]pattern, len(g.patterns))
+
+	for i, p := range g.patterns {
+		if strings.ContainsAny(p.pattern, "[\\") {
+			p.flags |= flagFold
+		} else {
+			p.pattern = FoldPath(p.pattern)
+		}
+
+		v.patterns[i] = p
+	}
+
+	return v
+}
+
+// FoldPath lowers the ASCII letters of s, the folding CaseFold applies, for
+// use with a matcher returned by LowerFold. It returns s itself when there is
+// nothing to lower.
+func FoldPath(s string) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return 'A' <= r && r <= 'Z' })
+	if i < 0 {
+		return s
+	}
+
+	b := []byte(s)
+
+	for ; i < len(b); i++ {
+		b[i] = lowerASCII(b[i])
+	}
+
+	return string(b)
+}
@@ -0,0 +1,81 @@
+package gitignore_test
+
+import (
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestLowerFoldEquivalence(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"*.LOG",
+		"!Keep.log",
+		"Build/",
+		"/Docs/**",
+		"SRC/**/Gen_*.go",
+		"\\Readme",
+		"[Z-a]x",
+		"[[:upper:]]*.txt",
+		"![[:lower:]]ib.txt",
+		"*.[Pp][Yy]c",
+		"cafÉ",
+		"b**/?",
+		"A**/b",
+	}
+
+	folded := gitignore.NewOptions(gitignore.Options{CaseFold: true}, lines...)
+	lowered := folded.LowerFold()
+
+	paths := []string{
+		"app.log", "APP.LOG", "keep.LOG", "KEEP.log",
+		"build", "BUILD/out.o", "docs/a/b", "DOCS/A", "Docs",
+		"src/x/y/gen_a.go", "Src/GEN_B.GO",
+		"readme", "README",
+		"_x", "^X", "Ax", "zx", "`X",
+		"a.txt", "A.TXT", "lib.txt", "LIB.TXT",
+		"m.pyc", "M.PYC",
+		"cafÉ", "café", "CAFÉ",
+		"ba/ab", "BA/AB", "a/c/ba/b", "A/C/BA/B",
+	}
+
+	for _, path := range paths {
+		for _, isDir := range []bool{false, true} {
+			want := folded.Match(path, isDir)
+			got := lowered.Match(gitignore.FoldPath(path), isDir)
+
+			if got.Ignored != want.Ignored || got.Pattern != want.Pattern {
+				t.Errorf("Match(%q, %v) = %+v, CaseFold reports %+v", path, isDir, got, want)
+			}
+
+			if got := lowered.Ignored(gitignore.FoldPath(path), isDir); got != want.Ignored {
+				t.Errorf("Ignored(%q, %v) = %v, CaseFold reports %v", path, isDir, got, want.Ignored)
+			}
+		}
+	}
+
+	if !slices.Equal(lowered.Patterns(), lines) {
+		t.Errorf("Patterns() = %q, want the patterns as given", lowered.Patterns())
+	}
+}
+
+func TestFoldPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "src/main.go", want: "src/main.go"},
+		{in: "Src/MAIN.go", want: "src/main.go"},
+		{in: "CAFÉ", want: "cafÉ"}, // only ASCII letters fold
+	}
+
+	for _, tc := range tests {
+		if got := gitignore.FoldPath(tc.in); got != tc.want {
+			t.Errorf("FoldPath(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
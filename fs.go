@@ -0,0 +1,161 @@
+package gitignore
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// FilteredFS returns a view of fsys that hides every path g ignores, so that
+// fs.WalkDir, fs.ReadDir and similar helpers transparently skip them.
+// Paths are matched relative to the root of fsys. ReadDir omits ignored
+// entries, and Open and Stat fail with fs.ErrNotExist for ignored paths.
+// A directory is hidden only when g ignores the directory itself and, per
+// ShouldSkipDir, nothing below it can be re-included; a directory re-included
+// by a negation stays visible.
+func FilteredFS(fsys fs.FS, g *GitIgnore) fs.FS {
+	return filteredFS{fsys: fsys, g: g}
+}
+
+// MarkEntries returns the decision for each entry of directory dir, as read
+// with a single fs.ReadDir, in order. Each result equals
+// Match(path.Join(dir, e.Name()), e.IsDir()), but dir and its ancestors are
+// evaluated once for the whole listing rather than once per entry.
+func (g *GitIgnore) MarkEntries(dir string, entries []fs.DirEntry) []Match {
+	out := make([]Match, len(entries))
+	seen := make(map[string]int)
+
+	for i, e := range entries {
+		out[i] = g.decideIn(g.opts.keepDir(path.Join(dir, e.Name()), e.IsDir()), e.IsDir(), seen).match
+	}
+
+	return out
+}
+
+// filteredFS implements FilteredFS.
+type filteredFS struct {
+	// the underlying filesystem
+	fsys fs.FS
+	// the matcher deciding visibility
+	g *GitIgnore
+}
+
+// Open opens name unless it is ignored.
+func (f filteredFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	if f.hidden(name, info.IsDir()) {
+		file.Close()
+
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if dir, ok := file.(fs.ReadDirFile); ok && info.IsDir() {
+		return &filteredDir{ReadDirFile: dir, name: name, f: f}, nil
+	}
+
+	return file, nil
+}
+
+// Stat returns file information for name unless it is ignored.
+func (f filteredFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.hidden(name, info.IsDir()) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return info, nil
+}
+
+// ReadDir reads the directory name, omitting ignored entries.
+func (f filteredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if _, err := f.Stat(name); err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(f.fsys, name)
+
+	return f.filter(name, entries), err
+}
+
+// hidden reports whether name is ignored. The root is always visible.
+func (f filteredFS) hidden(name string, isDir bool) bool {
+	if name == "." {
+		return false
+	}
+
+	if isDir {
+		return f.g.ShouldSkipDir(name)
+	}
+
+	return f.g.Ignored(name, false)
+}
+
+// filter drops the ignored entries of directory dir, preserving order.
+func (f filteredFS) filter(dir string, entries []fs.DirEntry) []fs.DirEntry {
+	out := make([]fs.DirEntry, 0, len(entries))
+
+	for _, e := range entries {
+		if !f.hidden(path.Join(dir, e.Name()), e.IsDir()) {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// filteredDir is an open directory whose ReadDir omits ignored entries.
+type filteredDir struct {
+	fs.ReadDirFile
+
+	// the directory name within the filesystem
+	name string
+	// the filesystem view the directory was opened from
+	f filteredFS
+}
+
+// ReadDir reads up to n visible entries, following the fs.ReadDirFile contract.
+func (d *filteredDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries, err := d.ReadDirFile.ReadDir(n)
+
+		return d.f.filter(d.name, entries), err
+	}
+
+	var out []fs.DirEntry
+
+	for len(out) < n {
+		entries, err := d.ReadDirFile.ReadDir(n - len(out))
+
+		out = append(out, d.f.filter(d.name, entries)...)
+
+		if err != nil {
+			if len(out) > 0 && errors.Is(err, io.EOF) {
+				return out, nil
+			}
+
+			return out, err
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package gitignore_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestFilteredFS(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "vendor/**", "!vendor/lib/", "!vendor/lib/keep.txt", ".git/")
+
+	fsys := gitignore.FilteredFS(testFS(), g)
+
+	// fstest.TestFS checks the view is self-consistent and exactly these files are visible.
+	if err := fstest.TestFS(fsys, "src/main.go", "docs/readme.md", "vendor/lib/keep.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"app.log", "src/debug.log", "build", "build/out.bin", "vendor/lib/file.go"} {
+		if _, err := fs.Stat(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q): got %v, want fs.ErrNotExist", name, err)
+		}
+
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%q): got %v, want fs.ErrNotExist", name, err)
+		}
+	}
+
+	var walked []string
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			walked = append(walked, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"docs/readme.md", "src/main.go", "vendor/lib/keep.txt"}
+	if len(walked) != len(want) {
+		t.Fatalf("WalkDir visited %q, want %q", walked, want)
+	}
+
+	for i := range want {
+		if walked[i] != want[i] {
+			t.Errorf("WalkDir visited %q, want %q", walked, want)
+
+			break
+		}
+	}
+}
+
+func TestMarkEntries(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "vendor/**", "!vendor/lib/", "!vendor/lib/keep.txt", ".git/")
+	fsys := testFS()
+
+	err := fs.WalkDir(fsys, ".", func(dir string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return err
+		}
+
+		got := g.MarkEntries(dir, entries)
+		if len(got) != len(entries) {
+			t.Fatalf("MarkEntries(%q) returned %d results for %d entries", dir, len(got), len(entries))
+		}
+
+		for i, e := range entries {
+			name := path.Join(dir, e.Name())
+			if want := g.Match(name, e.IsDir()); got[i] != want {
+				t.Errorf("MarkEntries(%q)[%d] = %+v, Match(%q) reports %+v", dir, i, got[i], name, want)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -10,8 +10,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
 )
 
 // TestGitCheckIgnore validates YAML test specifications against actual Git check-ignore behavior.
@@ -153,7 +157,7 @@ func runGitCheckIgnoreTest(t *testing.T, spec GitIgnore, c Case, extraArgs ...st
 
 	args := []string{
 		"-c", "core.excludesfile=/dev/null",
-		"-c", "core.ignorecase=false",
+		"-c", "core.ignorecase=" + strconv.FormatBool(spec.IgnoreCase),
 		"check-ignore",
 	}
 
@@ -238,3 +242,192 @@ func runValidatorCmd(workingDir, name string, args ...string) (string, error) {
 
 	return out.String(), err
 }
+
+// TestMatchBatchGit compares MatchBatch with the multi-argument form of
+// git check-ignore -v -n, which reports the deciding pattern of every path
+// in argument order.
+func TestMatchBatchGit(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"build/", "!build/keep", "*.log", "!important.log", "docs/**", "!docs/readme.md"}
+	paths := []string{
+		"build/out.o", "build/keep", "build", "app.log", "important.log", "src/app.log",
+		"docs/a/b.md", "docs/readme.md", "docs", "src/main.go", "src",
+	}
+
+	// Directories are passed without a trailing '/', which git check-ignore
+	// would match literally against patterns such as "docs/**".
+	dirs := map[string]bool{"build": true, "docs": true, "src": true}
+
+	tmp := t.TempDir()
+
+	if out, err := runValidatorCmd(tmp, "git", "init", "-q"); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("write .gitignore: %v", err)
+	}
+
+	for _, p := range paths {
+		target := filepath.Join(tmp, filepath.FromSlash(p))
+		if dirs[p] {
+			target = filepath.Join(target, ".keep")
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			t.Fatalf("mkdir parents for %q: %v", p, err)
+		}
+
+		if err := os.WriteFile(target, []byte("x"), 0o600); err != nil {
+			t.Fatalf("write file %q: %v", target, err)
+		}
+	}
+
+	args := append([]string{"-c", "core.excludesfile=/dev/null", "check-ignore", "-v", "-n", "--"}, paths...)
+
+	stdout, stderr, code := runValidatorGit(tmp, args...)
+	if code > 1 {
+		t.Fatalf("git check-ignore failed with exit code %d: %s", code, stderr)
+	}
+
+	outLines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(outLines) != len(paths) {
+		t.Fatalf("git check-ignore printed %d lines for %d paths:\n%s", len(outLines), len(paths), stdout)
+	}
+
+	got := gitignore.New(lines...).MatchBatch(paths, func(p string) bool { return dirs[p] })
+
+	for i, line := range outLines {
+		source, path, _ := strings.Cut(line, "\t")
+
+		// source is "<file>:<line>:<pattern>", or "::" when nothing matched.
+		fields := strings.SplitN(source, ":", 3)
+		if path != paths[i] || len(fields) != 3 {
+			t.Fatalf("unexpected git check-ignore line %q for %q", line, paths[i])
+		}
+
+		wantLine := 0
+		if fields[1] != "" {
+			wantLine, _ = strconv.Atoi(fields[1])
+		}
+
+		if got[i].Pattern != fields[2] || got[i].Line != wantLine {
+			t.Errorf("MatchBatch()[%d] for %q = %q at line %d, git reports %q at line %d",
+				i, paths[i], got[i].Pattern, got[i].Line, fields[2], wantLine)
+		}
+	}
+}
+
+// TestFromWorkingDirGit compares FromWorkingDir with git check-ignore -v -n
+// run from a subdirectory of the repository, passing the same
+// subdirectory-relative paths to both.
+func TestFromWorkingDirGit(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"/top", "sub/out/", "*.log", "!/sub/keep.log", "!other.log", "inner/"}
+	files := []string{"top", "sub/a.log", "sub/keep.log", "sub/out/x", "sub/inner/y.txt", "other/other.log", "sub/z.txt"}
+	paths := []string{
+		".", "..", "a.log", "keep.log", "out", "out/x", "./inner", "inner/y.txt",
+		"../top", "../sub/a.log", "../other/other.log", "z.txt", "x/../a.log",
+	}
+
+	// Directories are passed without a trailing '/', see TestMatchBatchGit.
+	dirs := map[string]bool{".": true, "..": true, "out": true, "./inner": true}
+
+	tmp := t.TempDir()
+
+	if out, err := runValidatorCmd(tmp, "git", "init", "-q"); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("write .gitignore: %v", err)
+	}
+
+	for _, f := range files {
+		target := filepath.Join(tmp, filepath.FromSlash(f))
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			t.Fatalf("mkdir parents for %q: %v", f, err)
+		}
+
+		if err := os.WriteFile(target, []byte("x"), 0o600); err != nil {
+			t.Fatalf("write file %q: %v", target, err)
+		}
+	}
+
+	args := append([]string{"-c", "core.excludesfile=/dev/null", "check-ignore", "-v", "-n", "--"}, paths...)
+
+	stdout, stderr, code := runValidatorGit(filepath.Join(tmp, "sub"), args...)
+	if code > 1 {
+		t.Fatalf("git check-ignore failed with exit code %d: %s", code, stderr)
+	}
+
+	outLines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(outLines) != len(paths) {
+		t.Fatalf("git check-ignore printed %d lines for %d paths:\n%s", len(outLines), len(paths), stdout)
+	}
+
+	m := gitignore.New(lines...).FromWorkingDir("sub")
+
+	for i, line := range outLines {
+		source, path, _ := strings.Cut(line, "\t")
+
+		// source is "<file>:<line>:<pattern>", or "::" when nothing matched.
+		fields := strings.SplitN(source, ":", 3)
+		if path != paths[i] || len(fields) != 3 {
+			t.Fatalf("unexpected git check-ignore line %q for %q", line, paths[i])
+		}
+
+		if got := m.Match(paths[i], dirs[paths[i]]); got.Pattern != fields[2] {
+			t.Errorf("FromWorkingDir(\"sub\").Match(%q) decided by %q, git reports %q", paths[i], got.Pattern, fields[2])
+		}
+	}
+}
+
+// TestSuggestReincludeGit appends the lines suggested by SuggestReinclude to
+// the patterns and checks with git check-ignore that they re-include the path,
+// and that the last line alone does not when ancestors had to be re-included.
+func TestSuggestReincludeGit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+	}{
+		{patterns: []string{"*.log"}, path: "logs/keep.log"},
+		{patterns: []string{"build/", "*.txt"}, path: "build/keep.txt"},
+		{patterns: []string{"build/**"}, path: "build/a/b/keep.txt"},
+		{patterns: []string{"vendor/", "!vendor/lib/keep.txt"}, path: "vendor/lib/keep.txt"},
+		{patterns: []string{"/*", "cache/"}, path: "src/cache", isDir: true},
+		{patterns: []string{"*"}, path: "a[1]*.txt"},
+	}
+
+	for _, tc := range tests {
+		lines := gitignore.New(tc.patterns...).SuggestReinclude(tc.path, tc.isDir)
+		if len(lines) == 0 {
+			t.Fatalf("%q: no suggestion for %q", tc.patterns, tc.path)
+		}
+
+		c := Case{Path: tc.path, Dir: tc.isDir, Ignored: false}
+
+		spec := GitIgnore{Name: tc.path, Gitignore: strings.Join(append(slices.Clone(tc.patterns), lines...), "\n")}
+		if res := runGitCheckIgnoreTest(t, spec, c); !res.Pass {
+			t.Errorf("%q with %q: git still ignores %q", tc.patterns, lines, tc.path)
+		}
+
+		if len(lines) == 1 {
+			continue
+		}
+
+		c.Ignored = true
+
+		spec.Gitignore = strings.Join(append(slices.Clone(tc.patterns), lines[len(lines)-1]), "\n")
+		if res := runGitCheckIgnoreTest(t, spec, c); !res.Pass {
+			t.Errorf("%q with only %q: git re-includes %q, so %q were not needed", tc.patterns, lines[len(lines)-1],
+				tc.path, lines[:len(lines)-1])
+		}
+	}
+}
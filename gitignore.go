@@ -3,9 +3,11 @@ package gitignore
 
 import (
 	"path"
+	"slices"
 	"strings"
 
 	wildmatch "github.com/idelchi/go-gitignore/wildmatch"
+	"golang.org/x/text/unicode/norm"
 )
 
 // patternFlag is a bitmask describing properties of a compiled pattern.
@@ -23,6 +25,13 @@ const (
 
 	// flagEndsWith marks an optimized pattern of the form "*literal".
 	flagEndsWith
+
+	// flagContents marks an optimized pattern of the form "literal/**".
+	flagContents
+
+	// flagFold marks a pattern of a LowerFold matcher that still folds each
+	// byte, because lowering it would change a bracket expression or an escape.
+	flagFold
 )
 
 // pattern is the compiled representation of a single .gitignore pattern.
@@ -37,6 +46,15 @@ type pattern struct {
 	nowildcardlen int
 	// patternFlag bitmask describing pattern traits.
 	flags patternFlag
+	// number of '/' a matching path must contain, or -1 if it can vary.
+	slashes int
+	// name of the source the pattern was added from, if any.
+	source string
+	// 1-based line of the pattern among the lines it was added with.
+	line int
+	// lower bound on the byte length of any path (without the rooting '/')
+	// or component the pattern matches, see wildmatch.MinLen.
+	minlen int
 }
 
 // GitIgnore holds a sequence of compiled patterns. Construct with New or NewOptions.
@@ -46,14 +64,102 @@ type GitIgnore struct {
 	patterns []pattern
 	// matcher options
 	opts Options
+	// number of patterns that are negated or not basename-only
+	nonBasename int
+	// number of patterns containing "**"
+	globstars int
+	// optional cache of Ignored results (see WithCache)
+	cache *lru
+	// pattern strings shared across Append calls when Options.Intern is set
+	interned interner
 }
 
 // Options defines matcher-wide behavior.
 type Options struct {
 	// CaseFold enables ASCII-only case-insensitive matching in the underlying wildmatch engine.
 	CaseFold bool
+	// CaseFoldFunc, when non-nil, is called with each component of the path being
+	// matched and decides whether that component is compared case-insensitively,
+	// overriding CaseFold. It only changes how bytes are compared, never which
+	// patterns are considered.
+	CaseFoldFunc func(component string) bool
+	// CommentPrefix is the byte starting a comment line. Zero means '#'.
+	// A leading '\' before it makes the line a literal pattern.
+	CommentPrefix byte
+	// NegationPrefix is the byte marking a negated pattern. Zero means '!'.
+	// A leading '\' before it makes the line a literal pattern.
+	NegationPrefix byte
+	// Strict makes Compile reject options and patterns outside portable Git
+	// semantics, as reported by Validate.
+	Strict bool
+	// NormalizeUnicode applies Unicode NFC normalization to patterns and paths
+	// before matching, so NFD-decomposed names (as returned on macOS) match
+	// NFC-composed patterns, like Git's core.precomposeUnicode. Matching stays
+	// byte-oriented afterwards: '?' and bracket classes consume single bytes.
+	NormalizeUnicode bool
+	// AllowReincludeUnderExcluded lets a matching negation re-include a path
+	// even when one of its ancestor directories is excluded, which Git never
+	// does. The excluded ancestors themselves are still reported as ignored,
+	// but DirMayContainIncluded and ShouldSkipDir treat them as rescued when a
+	// negation could match below them, so walkers still descend into them.
+	// This deliberately diverges from Git and is off by default.
+	AllowReincludeUnderExcluded bool
+	// PathTransform, when non-nil, rewrites every input path before anything
+	// else looks at it, including the check for absolute paths, NormalizeUnicode
+	// and path.Clean. Use it to adapt callers' paths, e.g. with filepath.ToSlash
+	// or strings.ToLower. It is never applied to patterns.
+	PathTransform func(pathname string) string
+	// InferDirFromSlash makes Match, MatchDirect, MatchFull and Ignored take
+	// directory-ness from the path itself, as IgnoredPath does: a trailing '/'
+	// (after PathTransform) marks a directory and is stripped. The isDir
+	// argument is then ignored, so "build" is matched as a file even when
+	// isDir is true, and "build/" as a directory even when it is false.
+	// Methods that learn the kind some other way, such as IgnoredPath,
+	// IgnoredDir, ShouldSkipDir and Walk, keep treating directories as such.
+	InferDirFromSlash bool
+	// MaxPatternLength bounds the length in bytes of a pattern line, guarding
+	// against adversarial input. Longer lines are dropped as if they were
+	// comments, and Validate reports them. Zero means DefaultMaxPatternLength;
+	// a negative value disables the bound.
+	MaxPatternLength int
+	// MaxStarstar bounds how many '**' can be active at once when a path is
+	// matched against a pattern, as wildmatch.WMOptions.MaxStarstar, which
+	// caps the backtracking of untrusted patterns; a pattern needing more does
+	// not match. Basename patterns are matched without Pathname, so each of
+	// their '*' counts. Zero means no limit. Git has no such limit, so
+	// Validate reports a positive value.
+	MaxStarstar int
+	// NormalizeSlashes canonicalizes generated patterns when they are compiled:
+	// runs of '/' collapse into one and leading "./" components anchor the
+	// pattern at the root, so "./build//out/" matches like "/build/out/", and
+	// "./" alone is dropped. Git does neither, so such patterns otherwise match
+	// nothing (see Validate). Patterns and Match.Pattern keep reporting the
+	// lines as given.
+	NormalizeSlashes bool
+	// IgnoreNegations compiles a matcher that can only ever exclude: negated
+	// lines are dropped as if they were comments, so no rule can re-include a
+	// path another rule ignores. Use it when the patterns come from files a
+	// user controls and an escape hatch such as "!secrets/" would be a risk,
+	// e.g. for a deny-list applied to uploads. Escaped "\!" lines are literal
+	// patterns and stay as they are.
+	IgnoreNegations bool
+	// NegationsAsPatterns, with IgnoreNegations, compiles each negated line as
+	// the positive pattern after its '!' instead of dropping it, for when a
+	// line such as "!*.key" is more likely a mistaken exclusion than an
+	// exception. Either way Ignored only grows the ignored set. Patterns and
+	// Match.Pattern keep reporting the lines as given.
+	NegationsAsPatterns bool
+	// Intern makes identical pattern strings share storage, so matchers built
+	// from concatenated templates that repeat the same rules many times keep
+	// one copy of each. The first occurrence of each line is copied rather
+	// than kept as a slice of the input. It never changes matching.
+	Intern bool
 }
 
+// DefaultMaxPatternLength is the pattern length bound used when
+// Options.MaxPatternLength is zero.
+const DefaultMaxPatternLength = 1 << 16
+
 // New compiles .gitignore-style lines using default Options.
 func New(lines ...string) *GitIgnore {
 	return NewOptions(Options{}, lines...)
@@ -61,15 +167,11 @@ func New(lines ...string) *GitIgnore {
 
 // NewOptions compiles .gitignore-style lines with explicit options.
 func NewOptions(opt Options, lines ...string) *GitIgnore {
-	patterns := make([]pattern, 0, len(lines))
+	g := &GitIgnore{patterns: make([]pattern, 0, len(lines)), opts: opt}
 
-	for _, line := range lines {
-		if p := parsePattern(line); p != nil {
-			patterns = append(patterns, *p)
-		}
-	}
+	g.Append(lines...)
 
-	return &GitIgnore{patterns: patterns, opts: opt}
+	return g
 }
 
 // Patterns returns the original patterns in their input order.
@@ -83,13 +185,120 @@ func (g *GitIgnore) Patterns() []string {
 	return out
 }
 
-// Append compiles and appends new patterns, preserving last-match-wins order.
-func (g *GitIgnore) Append(lines ...string) {
-	for _, line := range lines {
-		if p := parsePattern(line); p != nil {
-			g.patterns = append(g.patterns, *p)
+// HasGlobstar reports whether any compiled pattern contains "**", wherever it
+// appears. Strategies that assume '*' never crosses '/', such as indexing
+// patterns by their literal directory prefix, are safe when it is false.
+// "**/*literal" patterns compile to their basename form and do not count.
+func (g *GitIgnore) HasGlobstar() bool {
+	return g.globstars > 0
+}
+
+// CommonRootPrefix returns the longest '/'-aligned literal directory prefix
+// shared by every anchored, non-negated pattern, such as "src/app" for
+// "/src/app/*.go" and "src/app/build/". Only that path and paths below it can
+// be ignored, so a tool mounting the matcher at a subtree can skip the rest
+// without querying. It is empty when a basename pattern applies at any depth,
+// when the prefixes diverge, or when no pattern ignores anything. Prefixes are
+// compared byte for byte, even under case folding.
+func (g *GitIgnore) CommonRootPrefix() string {
+	var (
+		prefix string
+		found  bool
+	)
+
+	for _, p := range g.patterns {
+		if p.flags&flagNegative != 0 {
+			continue
+		}
+
+		if p.flags&flagNoDir != 0 {
+			return ""
+		}
+
+		lit := p.pattern[:p.nowildcardlen]
+		if p.nowildcardlen < p.patternlen {
+			lit = lit[:max(strings.LastIndexByte(lit, '/'), 0)]
 		}
+
+		lit = strings.TrimPrefix(lit, "/")
+
+		if found {
+			lit = commonDir(prefix, lit)
+		}
+
+		if lit == "" {
+			return ""
+		}
+
+		prefix, found = lit, true
 	}
+
+	return prefix
+}
+
+// commonDir returns the longest common prefix of the '/'-separated paths a
+// and b that ends on a component boundary in both.
+func commonDir(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	if (n == len(a) || a[n] == '/') && (n == len(b) || b[n] == '/') {
+		return a[:n]
+	}
+
+	return a[:max(strings.LastIndexByte(a[:n], '/'), 0)]
+}
+
+// Append compiles and appends new patterns after the existing ones, preserving
+// last-match-wins order. It returns the number of patterns added, which is
+// less than len(lines) when some lines are inert (comments, blank lines).
+func (g *GitIgnore) Append(lines ...string) int {
+	return g.appendSource("", lines)
+}
+
+// appendSource compiles lines and appends them, recording source and line
+// numbers, and returns the number of patterns added.
+func (g *GitIgnore) appendSource(source string, lines []string) int {
+	g.patterns = slices.Grow(g.patterns, len(lines))
+	before := len(g.patterns)
+
+	if g.cache != nil {
+		defer g.cache.clear()
+	}
+
+	if g.opts.Intern && g.interned == nil {
+		g.interned = make(interner)
+	}
+
+	for i, line := range lines {
+		if p, ok := parsePattern(line, g.opts); ok {
+			p.source = source
+			p.line = i + 1
+
+			if g.interned != nil {
+				g.interned.pattern(&p)
+			}
+
+			g.add(p)
+		}
+	}
+
+	return len(g.patterns) - before
+}
+
+// add appends a compiled pattern and updates the matcher-wide traits.
+func (g *GitIgnore) add(p pattern) {
+	if p.flags&flagNegative != 0 || p.flags&flagNoDir == 0 {
+		g.nonBasename++
+	}
+
+	if strings.Contains(p.pattern, "**") {
+		g.globstars++
+	}
+
+	g.patterns = append(g.patterns, p)
 }
 
 // Match is a detailed result mirroring `git check-ignore -v` semantics.
@@ -98,66 +307,560 @@ func (g *GitIgnore) Append(lines ...string) {
 type Match struct {
 	Ignored bool
 	Pattern string
+	// Reason tells which branch of the decision produced the result.
+	Reason Reason
+	// Source names where the deciding pattern came from (see Builder), and is
+	// empty for patterns added through New, NewOptions or Append.
+	Source string
+	// Line is the 1-based position of the deciding pattern among the lines it
+	// was added with, counting comments and blank lines, or 0 if none decided.
+	Line int
+}
+
+// Reason classifies how a Match was decided.
+type Reason uint8
+
+const (
+	// ReasonNoMatch means no pattern applied to the path or its ancestors.
+	ReasonNoMatch Reason = iota
+	// ReasonIgnored means a positive pattern matched the path itself.
+	ReasonIgnored
+	// ReasonNegated means a negated pattern matched the path and re-included it.
+	ReasonNegated
+	// ReasonParentExcluded means an excluded ancestor directory decided the result,
+	// either because nothing matched the path or because a negation could not rescue it.
+	ReasonParentExcluded
+)
+
+// String returns a short lower-case name for the reason.
+func (r Reason) String() string {
+	switch r {
+	case ReasonNoMatch:
+		return "no match"
+	case ReasonIgnored:
+		return "ignored"
+	case ReasonNegated:
+		return "negated"
+	case ReasonParentExcluded:
+		return "parent excluded"
+	default:
+		return "unknown"
+	}
 }
 
 // Match returns a detailed match result, including the deciding pattern.
 // If no rule directly matches but an ancestor directory is excluded, the
 // ancestor’s pattern is returned.
+//
+// The current directory (".", or any path cleaning to it) is matched the way
+// Git matches its empty path: only non-directory-only basename patterns that
+// match the empty string, such as "*", apply to it. Paths that still climb
+// out of the root after cleaning ("..", "../x", "a/../../x") are outside
+// the tree the patterns describe and are never ignored; ones that stay inside
+// ("a/../b") are matched as their cleaned form.
 func (g *GitIgnore) Match(pathname string, isDir bool) Match {
+	return g.decide(pathname, isDir).match
+}
+
+// MatchDirect returns the raw last-match-wins result for the path itself.
+// Unlike Match, it never consults ancestor directories: a path below an
+// excluded directory is not ignored unless a pattern matches the path itself,
+// and a negation matching it always re-includes it, so Reason is never
+// ReasonParentExcluded. Use it to explain which rule matches a path or when
+// the directory tree is not materialized; use Match to decide whether Git
+// ignores the path.
+func (g *GitIgnore) MatchDirect(pathname string, isDir bool) Match {
+	return g.decideDirect(pathname, isDir).match
+}
+
+// MatchFull is like Match but also returns the excluded ancestor directory
+// that decided the result, so callers can prune from that level. The ancestor
+// is empty when the path itself decided the result or nothing matched.
+func (g *GitIgnore) MatchFull(pathname string, isDir bool) (Match, string) {
+	d := g.decide(pathname, isDir)
+
+	return d.match, d.ancestor
+}
+
+// decision is the internal outcome of evaluating a single path.
+type decision struct {
+	// the public result
+	match Match
+	// index of the deciding pattern, or -1 when no pattern decided
+	index int
+	// the excluded ancestor directory that decided the result, if any
+	ancestor string
+}
+
+// decide implements Match, additionally recording the deciding pattern index
+// and excluded ancestor.
+func (g *GitIgnore) decide(pathname string, isDir bool) decision {
+	return g.decideIn(pathname, isDir, nil)
+}
+
+// decideIn is decide sharing ancestor lookups through seen (see
+// parentExcludedIn).
+func (g *GitIgnore) decideIn(pathname string, isDir bool, seen map[string]int) decision {
+	pathname, isDir = g.opts.input(pathname, isDir)
+
+	return g.decideInput(pathname, isDir, seen)
+}
+
+// decideInput is decideIn for a path that has already been through input.
+func (g *GitIgnore) decideInput(pathname string, isDir bool, seen map[string]int) decision {
+	pathname, d, ok := g.clean(pathname)
+	if !ok {
+		return d
+	}
+
+	i := g.lastMatch(pathname, isDir)
+	if i >= 0 && (g.patterns[i].flags&flagNegative == 0 || g.opts.AllowReincludeUnderExcluded) {
+		return g.decidedBy(i)
+	}
+
+	// Nothing matched or a negation did; either way an excluded ancestor wins,
+	// as a negation cannot rescue a path below an excluded directory.
+	if parent, ancestor := g.parentExcludedIn(pathname, seen); parent >= 0 {
+		d := g.decided(parent, ReasonParentExcluded)
+		d.ancestor = ancestor
+
+		return d
+	}
+
+	return g.decidedBy(i)
+}
+
+// decideDirect implements MatchDirect: the last-match-wins result for the
+// path itself, without consulting its ancestors.
+func (g *GitIgnore) decideDirect(pathname string, isDir bool) decision {
+	pathname, isDir = g.opts.input(pathname, isDir)
+
+	pathname, d, ok := g.clean(pathname)
+	if !ok {
+		return d
+	}
+
+	return g.decidedBy(g.lastMatch(pathname, isDir))
+}
+
+// prepare transforms and cleans pathname for matching, as clean does.
+func (g *GitIgnore) prepare(pathname string) (string, decision, bool) {
+	return g.clean(g.opts.transform(pathname))
+}
+
+// clean cleans a transformed pathname for matching. When the path needs no
+// pattern scan (empty, absolute, outside the root, or the root itself) it
+// returns the final decision and false.
+func (g *GitIgnore) clean(pathname string) (string, decision, bool) {
 	if len(g.patterns) == 0 || pathname == "" || strings.HasPrefix(pathname, "/") {
-		return Match{Ignored: false, Pattern: ""}
+		return "", undecided(), false
 	}
 
-	pathname = path.Clean(pathname)
+	pathname = path.Clean(g.opts.normalize(pathname))
 
-	parentExcluded, parentPattern := g.parentExcludedWithPattern(pathname)
+	if pathname == "." {
+		return "", g.decideRoot(), false
+	}
+
+	if outsideRoot(pathname) {
+		return "", undecided(), false
+	}
+
+	return pathname, decision{}, true
+}
 
+// lastMatch returns the index of the last pattern matching the path itself,
+// or -1 if none does.
+func (g *GitIgnore) lastMatch(pathname string, isDir bool) int {
 	for i := len(g.patterns) - 1; i >= 0; i-- {
+		if g.matchesPattern(g.patterns[i], pathname, isDir) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// decided returns the decision made by the pattern at index i for reason.
+func (g *GitIgnore) decided(i int, reason Reason) decision {
+	p := &g.patterns[i]
+
+	return decision{
+		match: Match{
+			Ignored: reason != ReasonNegated,
+			Pattern: p.original,
+			Reason:  reason,
+			Source:  p.source,
+			Line:    p.line,
+		},
+		index: i,
+	}
+}
+
+// decidedBy returns the decision made by the pattern at index i according to
+// its sign, or undecided if i is negative.
+func (g *GitIgnore) decidedBy(i int) decision {
+	switch {
+	case i < 0:
+		return undecided()
+	case g.patterns[i].flags&flagNegative != 0:
+		return g.decided(i, ReasonNegated)
+	default:
+		return g.decided(i, ReasonIgnored)
+	}
+}
+
+// undecided is the decision when no pattern applies.
+func undecided() decision {
+	return decision{match: Match{Ignored: false, Pattern: "", Reason: ReasonNoMatch}, index: -1}
+}
+
+// decideRoot decides the current directory ("."), which Git evaluates as the
+// empty path: it has no ancestors, directory-only and path patterns never
+// match it, and a basename pattern matches only if it matches the empty
+// string (e.g. "*"). Negations apply as usual, so "*" followed by "!*"
+// leaves "." not ignored while "!." never matches it.
+func (g *GitIgnore) decideRoot() decision {
+	for i := len(g.patterns) - 1; i >= 0; i-- {
+		p := &g.patterns[i]
+
+		if p.flags&flagNoDir == 0 || p.flags&flagDirOnly != 0 {
+			continue
+		}
+
+		if g.matchBasename("", p) {
+			return g.decidedBy(i)
+		}
+	}
+
+	return undecided()
+}
+
+// Ignored reports whether a relative path should be ignored.
+// The caller must indicate if the path is a directory.
+func (g *GitIgnore) Ignored(pathname string, isDir bool) bool {
+	if g.cache == nil {
+		return g.ignored(pathname, isDir)
+	}
+
+	key := cacheKey{pathname: pathname, isDir: isDir}
+	if ignored, ok := g.cache.get(key); ok {
+		return ignored
+	}
+
+	ignored := g.ignored(pathname, isDir)
+	g.cache.put(key, ignored)
+
+	return ignored
+}
+
+// ignored implements Ignored without the cache.
+func (g *GitIgnore) ignored(pathname string, isDir bool) bool {
+	if len(g.patterns) > 0 && g.nonBasename == 0 {
+		return g.ignoredBasenames(pathname, isDir)
+	}
+
+	return g.Match(pathname, isDir).Ignored
+}
+
+// IgnoredDir reports whether the directory dir should be ignored, exactly as
+// Ignored(dir, true) does, taking dir as a directory even under
+// Options.InferDirFromSlash. It is meant for walkers deciding whether to prune:
+// it stops as soon as a pattern matching dir itself decides it, without
+// building a Match, and only then checks dir's ancestors.
+func (g *GitIgnore) IgnoredDir(dir string) bool {
+	if len(g.patterns) > 0 && g.nonBasename == 0 {
+		return g.ignoredBasenames(g.opts.keepDir(dir, true), true)
+	}
+
+	dir, d, ok := g.prepare(dir)
+	if !ok {
+		return d.match.Ignored
+	}
+
+	if i := g.lastMatch(dir, true); i >= 0 {
+		if g.patterns[i].flags&flagNegative == 0 {
+			return true
+		}
+
+		if g.opts.AllowReincludeUnderExcluded {
+			return false
+		}
+	}
+
+	parent, _ := g.parentExcluded(dir)
+
+	return parent >= 0
+}
+
+// IgnoredEither reports whether pathname would be ignored as a file and as a
+// directory, for paths whose kind is unknown, e.g. because they do not exist
+// yet. Unless Options.InferDirFromSlash is set, the results equal
+// Ignored(pathname, false) and Ignored(pathname, true). With it, a path with a
+// trailing '/' is a directory either way, while one without is still tried as
+// both, the second result then equaling IgnoredDir(pathname) rather than
+// Ignored, which would take it for a file. Either way the path is normalized
+// and the patterns are scanned only once.
+func (g *GitIgnore) IgnoredEither(pathname string) (ignoredIfFile, ignoredIfDir bool) {
+	// A trailing '/' that InferDirFromSlash honors settles the kind.
+	if _, isDir := g.opts.input(pathname, false); isDir {
+		ignored := g.Ignored(pathname, true)
+
+		return ignored, ignored
+	}
+
+	pathname, d, ok := g.prepare(pathname)
+	if !ok {
+		return d.match.Ignored, d.match.Ignored
+	}
+
+	// Only directory-only patterns tell the two apart, so one scan finds the
+	// last pattern matching either kind.
+	file, dir := -1, -1
+
+	for i := len(g.patterns) - 1; i >= 0 && (file < 0 || dir < 0); i-- {
 		p := g.patterns[i]
 
-		if !g.matchesPattern(p, pathname, isDir) {
+		if (file >= 0 && p.flags&flagDirOnly == 0) || !g.matchesPattern(p, pathname, true) {
 			continue
 		}
 
-		if p.flags&flagNegative != 0 {
-			// Special-case current directory: a negation must NOT rescue '.'
-			// Treat it as if the negation rule does not apply and continue
-			// scanning earlier patterns to find the deciding positive rule.
-			if pathname == "." {
-				continue
-			}
+		if dir < 0 {
+			dir = i
+		}
 
-			// '..' can be rescued unless an ancestor is excluded.
-			if pathname == ".." {
-				if parentExcluded {
-					return Match{Ignored: true, Pattern: parentPattern}
-				}
+		if file < 0 && p.flags&flagDirOnly == 0 {
+			file = i
+		}
+	}
 
-				return Match{Ignored: false, Pattern: p.original}
-			}
+	// The excluded ancestor, shared by both answers, is looked up at most once.
+	const unknown = -2
 
-			// If an ancestor is excluded, a negation cannot rescue.
-			if parentExcluded {
-				return Match{Ignored: true, Pattern: parentPattern}
-			}
+	parent := unknown
 
-			return Match{Ignored: false, Pattern: p.original}
+	ignored := func(i int) bool {
+		switch {
+		case i >= 0 && g.patterns[i].flags&flagNegative == 0:
+			return true
+		case i >= 0 && g.opts.AllowReincludeUnderExcluded:
+			return false
+		case parent == unknown:
+			parent, _ = g.parentExcluded(pathname)
 		}
 
-		return Match{Ignored: true, Pattern: p.original}
+		return parent >= 0
 	}
 
-	if parentExcluded {
-		return Match{Ignored: true, Pattern: parentPattern}
+	return ignored(file), ignored(dir)
+}
+
+// IgnoredUntracked is like Ignored, but reports false for paths the caller's
+// index tracks, as Git never ignores tracked files whatever the patterns say.
+// tracked receives the path cleaned as for matching (relative, '/'-separated,
+// without "./" or a trailing '/'); a nil tracked treats every path as
+// untracked.
+func (g *GitIgnore) IgnoredUntracked(pathname string, isDir bool, tracked func(string) bool) bool {
+	if tracked != nil {
+		if cleaned, _, ok := g.prepare(pathname); ok && tracked(cleaned) {
+			return false
+		}
 	}
 
-	return Match{Ignored: false, Pattern: ""}
+	return g.Ignored(pathname, isDir)
 }
 
-// Ignored reports whether a relative path should be ignored.
-// The caller must indicate if the path is a directory.
-func (g *GitIgnore) Ignored(pathname string, isDir bool) bool {
-	return g.Match(pathname, isDir).Ignored
+// Reincluded reports whether a negation explicitly re-includes the path: the
+// deciding rule is a "!pattern" that actually applied. A path that is merely
+// not matched by any rule, or whose negation is overridden by an excluded
+// ancestor, is not re-included.
+func (g *GitIgnore) Reincluded(pathname string, isDir bool) bool {
+	return g.Match(pathname, isDir).Reason == ReasonNegated
+}
+
+// IgnoredPath is like Ignored but infers directory-ness from the path itself:
+// a trailing '/' marks a directory.
+func (g *GitIgnore) IgnoredPath(pathname string) bool {
+	return g.Ignored(g.pathIsDir(pathname, nil))
+}
+
+// Classify matches each path, inferring directory-ness from a trailing '/'
+// as IgnoredPath does, and returns one result per path in input order.
+func (g *GitIgnore) Classify(paths []string) []Match {
+	out := make([]Match, len(paths))
+
+	for i, p := range paths {
+		out[i] = g.Match(g.pathIsDir(p, nil))
+	}
+
+	return out
+}
+
+// TopLevelIgnored returns, in input order, the names among the root-level
+// entries names that Ignored reports as ignored. isDir is interpreted as in
+// Report. Root entries have no ancestors, so each name is decided by the last
+// pattern matching it alone; names that turn out not to be root-level after
+// cleaning fall back to Ignored.
+func (g *GitIgnore) TopLevelIgnored(names []string, isDir func(string) bool) []string {
+	var out []string
+
+	for _, name := range names {
+		if g.topLevelIgnored(g.pathIsDir(name, isDir)) {
+			out = append(out, name)
+		}
+	}
+
+	return out
+}
+
+// topLevelIgnored implements TopLevelIgnored for a single name.
+func (g *GitIgnore) topLevelIgnored(name string, isDir bool) bool {
+	pathname, dir := g.opts.input(name, isDir)
+
+	cleaned, d, ok := g.clean(pathname)
+	if !ok {
+		return d.match.Ignored
+	}
+
+	if strings.IndexByte(cleaned, '/') >= 0 {
+		return g.Ignored(name, isDir)
+	}
+
+	i := g.lastMatch(cleaned, dir)
+
+	return i >= 0 && g.patterns[i].flags&flagNegative == 0
+}
+
+// basename returns the final component of a cleaned '/'-separated path.
+func basename(pathname string) string {
+	return pathname[strings.LastIndexByte(pathname, '/')+1:]
+}
+
+// outsideRoot reports whether a cleaned relative path escapes the root.
+func outsideRoot(pathname string) bool {
+	return pathname == ".." || strings.HasPrefix(pathname, "../")
+}
+
+// IgnoredDirs reports, for each directory in dirs, whether Ignored(dir, true)
+// holds. Results for shared ancestors are computed once, so a whole tree can
+// be classified without re-evaluating every parent for every directory.
+// The returned map is keyed by the input strings.
+func (g *GitIgnore) IgnoredDirs(dirs []string) map[string]bool {
+	out := make(map[string]bool, len(dirs))
+	memo := make(map[string]bool)
+	seen := make(map[string]int)
+
+	for _, dir := range dirs {
+		cleaned, d, ok := g.prepare(dir)
+
+		switch {
+		case !ok:
+			out[dir] = d.match.Ignored
+		case g.opts.AllowReincludeUnderExcluded:
+			out[dir] = g.ignoredDirReinclude(cleaned, seen)
+		default:
+			out[dir] = g.ignoredDir(cleaned, memo)
+		}
+	}
+
+	return out
+}
+
+// ignoredDirReinclude is ignoredDir under Options.AllowReincludeUnderExcluded,
+// where an excluded ancestor no longer decides a directory that a pattern
+// matches itself. Ancestor lookups are shared through seen (see
+// parentExcludedIn).
+func (g *GitIgnore) ignoredDirReinclude(dir string, seen map[string]int) bool {
+	if i := g.lastMatch(dir, true); i >= 0 {
+		return g.patterns[i].flags&flagNegative == 0
+	}
+
+	parent, _ := g.parentExcludedIn(dir, seen)
+
+	return parent >= 0
+}
+
+// ignoredDir reports whether the cleaned directory path is ignored, memoizing
+// the result for it and its ancestors: a directory is ignored when an ancestor
+// is, or when the last pattern matching it is not a negation.
+func (g *GitIgnore) ignoredDir(dir string, memo map[string]bool) bool {
+	if ignored, ok := memo[dir]; ok {
+		return ignored
+	}
+
+	ignored := false
+
+	if slash := strings.LastIndexByte(dir, '/'); slash >= 0 {
+		ignored = g.ignoredDir(dir[:slash], memo)
+	}
+
+	if !ignored {
+		i := g.lastMatch(dir, true)
+		ignored = i >= 0 && g.patterns[i].flags&flagNegative == 0
+	}
+
+	memo[dir] = ignored
+
+	return ignored
+}
+
+// splitDirSuffix strips trailing '/' from pathname and reports whether any were present.
+func splitDirSuffix(pathname string) (string, bool) {
+	trimmed := strings.TrimRight(pathname, "/")
+
+	return trimmed, len(trimmed) != len(pathname)
+}
+
+// ignoredBasenames is the fast path of Ignored for matchers consisting solely of
+// non-negated basename-only patterns. Without negations, a path is ignored as soon
+// as any of its components matches, so each component is tested in place
+// without splitting the path or resolving ancestors separately.
+func (g *GitIgnore) ignoredBasenames(pathname string, isDir bool) bool {
+	pathname, isDir = g.opts.input(pathname, isDir)
+
+	if pathname == "" || strings.HasPrefix(pathname, "/") {
+		return false
+	}
+
+	pathname = path.Clean(g.opts.normalize(pathname))
+
+	if pathname == "." {
+		return g.decideRoot().match.Ignored
+	}
+
+	if outsideRoot(pathname) {
+		return false
+	}
+
+	for start := 0; start <= len(pathname); {
+		end := strings.IndexByte(pathname[start:], '/')
+
+		last := end < 0
+		if last {
+			end = len(pathname)
+		} else {
+			end += start
+		}
+
+		component := pathname[start:end]
+		componentIsDir := !last || isDir
+
+		for i := len(g.patterns) - 1; i >= 0; i-- {
+			p := &g.patterns[i]
+
+			if p.flags&flagDirOnly != 0 && !componentIsDir {
+				continue
+			}
+
+			if g.matchBasename(component, p) {
+				return true
+			}
+		}
+
+		start = end + 1
+	}
+
+	return false
 }
 
 // matchRooted handles patterns beginning with '/' (root-relative).
@@ -169,6 +872,14 @@ func (g *GitIgnore) matchRooted(p pattern, pathname string, isDir bool) bool {
 	pat := p.pattern[1:] // strip leading '/'
 	text := pathname
 
+	// Cheap rejection on path depth.
+	if p.slashes >= 0 && strings.Count(text, "/") != p.slashes {
+		return false
+	}
+
+	// The mask covers whole components, so it is built before the strip.
+	mask := g.foldMask(text)
+
 	// Adjust the literal-prefix length (we removed a leading '/').
 	lit := p.nowildcardlen
 
@@ -184,7 +895,7 @@ func (g *GitIgnore) matchRooted(p pattern, pathname string, isDir bool) bool {
 		lit = len(pat)
 	}
 
-	if lit > len(text) || pat[:lit] != text[:lit] {
+	if lit > len(text) || !g.equalFolded(pat[:lit], text[:lit], mask, p.flags) {
 		return false
 	}
 
@@ -196,10 +907,7 @@ func (g *GitIgnore) matchRooted(p pattern, pathname string, isDir bool) bool {
 		return text == ""
 	}
 
-	if !wildmatch.MatchOpt(pat, text, wildmatch.WMOptions{
-		Pathname: true,
-		CaseFold: g.opts.CaseFold,
-	}) {
+	if !wildmatch.MatchOpt(pat, text, g.wmOptions(tail(mask, lit), true, p.flags)) {
 		return false
 	}
 
@@ -212,6 +920,11 @@ func (g *GitIgnore) matchesPattern(p pattern, pathname string, isDir bool) bool
 		return false
 	}
 
+	// Optimized "literal/**" subtree check.
+	if p.flags&flagContents != 0 && g.opts.CaseFoldFunc == nil {
+		return g.matchContents(p, pathname)
+	}
+
 	// Rooted pattern.
 	if len(p.pattern) > 0 && p.pattern[0] == '/' {
 		return g.matchRooted(p, pathname, isDir)
@@ -219,36 +932,42 @@ func (g *GitIgnore) matchesPattern(p pattern, pathname string, isDir bool) bool
 
 	// Basename-only (no '/'): match against the final component only.
 	if p.flags&flagNoDir != 0 {
-		base := path.Base(pathname)
-
-		return g.matchBasename(base, p.pattern, p.nowildcardlen, p.patternlen, p.flags)
+		return g.matchBasename(basename(pathname), &p)
 	}
 
 	// Path-containing pattern: relative to root; do NOT slide.
 	pat := p.pattern
 	text := pathname
 
-	// Fast path for literal prefix.
+	// Cheap rejection on path depth.
+	if p.slashes >= 0 && strings.Count(text, "/") != p.slashes {
+		return false
+	}
+
+	// The mask covers whole components, so it is built before the strip.
+	mask := g.foldMask(text)
+
+	// Fast path for literal prefix. Like Git's match_pathname, only the rest
+	// goes through wildmatch, so a "**" right after the prefix is not one
+	// that starts the pattern.
 	if p.nowildcardlen > 0 && p.nowildcardlen <= len(pat) && p.nowildcardlen <= len(text) {
-		if pat[:p.nowildcardlen] != text[:p.nowildcardlen] {
+		if !g.equalFolded(pat[:p.nowildcardlen], text[:p.nowildcardlen], mask, p.flags) {
 			return false
 		}
 
 		pat = pat[p.nowildcardlen:]
 		text = text[p.nowildcardlen:]
+		mask = tail(mask, p.nowildcardlen)
 	} else if p.nowildcardlen > len(text) {
 		return false
 	}
 
 	// Entire pattern is literal.
 	if p.nowildcardlen == p.patternlen {
-		return pat == text
+		return g.equalFolded(pat, text, mask, p.flags)
 	}
 
-	if !wildmatch.MatchOpt(pat, text, wildmatch.WMOptions{
-		Pathname: true,
-		CaseFold: g.opts.CaseFold,
-	}) {
+	if !wildmatch.MatchOpt(pat, text, g.wmOptions(mask, true, p.flags)) {
 		return false
 	}
 
@@ -259,47 +978,192 @@ func (g *GitIgnore) matchesPattern(p pattern, pathname string, isDir bool) bool
 	return true
 }
 
-// matchBasename matches a single path component (no '/' inside).
-func (g *GitIgnore) matchBasename(basename, pattern string, nowildcardlen, patternlen int, pflags patternFlag) bool {
-	if patternlen == 0 {
+// matchContents matches a "literal/**" pattern, which matches every path
+// strictly below the literal directory but not the directory itself.
+func (g *GitIgnore) matchContents(p pattern, pathname string) bool {
+	dir := strings.TrimPrefix(p.pattern[:p.patternlen-len("**")], "/")
+
+	if len(pathname) <= len(dir) {
+		return false
+	}
+
+	if g.opts.CaseFold {
+		return hasPrefixFold(pathname, dir)
+	}
+
+	return strings.HasPrefix(pathname, dir)
+}
+
+// matchBasename matches a single path component (no '/' inside) against p.
+func (g *GitIgnore) matchBasename(basename string, p *pattern) bool {
+	pattern, pflags := p.pattern, p.flags
+
+	if p.patternlen == 0 {
 		return basename == ""
 	}
 
-	if nowildcardlen == patternlen {
-		return basename == pattern
+	// Every match is at least minlen bytes long, which rejects long patterns
+	// against short names without scanning them.
+	if len(basename) < p.minlen {
+		return false
 	}
 
-	// Optimized "*literal" suffix check.
-	if pflags&flagEndsWith != 0 && len(pattern) > 1 && pattern[0] == '*' {
+	// Optimized "*literal" suffix check. The suffix holds no escapes, so under
+	// CaseFold it compares like Git's fspathncmp: ASCII letters fold both ways.
+	if pflags&flagEndsWith != 0 && len(pattern) > 1 && pattern[0] == '*' && g.opts.CaseFoldFunc == nil {
+		if g.opts.CaseFold {
+			return hasSuffixFold(basename, pattern[1:])
+		}
+
 		return strings.HasSuffix(basename, pattern[1:])
 	}
 
-	return wildmatch.MatchOpt(pattern, basename, wildmatch.WMOptions{
-		Pathname: false,
-		CaseFold: g.opts.CaseFold,
-	})
+	mask := g.foldMask(basename)
+
+	if p.nowildcardlen == p.patternlen {
+		return g.equalFolded(pattern, basename, mask, pflags)
+	}
+
+	return wildmatch.MatchOpt(pattern, basename, g.wmOptions(mask, false, pflags))
+}
+
+// hasSuffixFold is strings.HasSuffix with ASCII-only case folding.
+func hasSuffixFold(s, suffix string) bool {
+	return len(s) >= len(suffix) && equalFoldASCII(s[len(s)-len(suffix):], suffix)
+}
+
+// hasPrefixFold is strings.HasPrefix with ASCII-only case folding.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && equalFoldASCII(s[:len(prefix)], prefix)
+}
+
+// equalFoldASCII reports whether a and b, of equal length, are equal under
+// ASCII-only case folding.
+func equalFoldASCII(a, b string) bool {
+	for i := range len(a) {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
-// parsePattern compiles a single .gitignore pattern line or returns nil.
+// lowerASCII returns the lower-case form of an ASCII letter, or c unchanged.
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+
+	return c
+}
+
+// folding reports whether any case folding may apply when comparing bytes
+// against a pattern with the given flags.
+func (g *GitIgnore) folding(pflags patternFlag) bool {
+	return g.opts.CaseFold || g.opts.CaseFoldFunc != nil || pflags&flagFold != 0
+}
+
+// equalFolded reports whether text equals the literal pat, comparing each
+// byte as wildmatch would under the same options: ASCII letters fold under
+// CaseFold, for patterns flagged to fold, and where mask (see foldMask) is set.
+func (g *GitIgnore) equalFolded(pat, text string, mask []bool, pflags patternFlag) bool {
+	if !g.folding(pflags) || len(pat) != len(text) {
+		return pat == text
+	}
+
+	all := g.opts.CaseFold || pflags&flagFold != 0
+
+	for i := range len(pat) {
+		if pat[i] == text[i] {
+			continue
+		}
+
+		if fold := all || mask != nil && mask[i]; !fold || lowerASCII(pat[i]) != lowerASCII(text[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// wmOptions returns the wildmatch options for matching text, whose fold mask
+// is mask, against a pattern with the given flags.
+func (g *GitIgnore) wmOptions(mask []bool, pathname bool, pflags patternFlag) wildmatch.WMOptions {
+	return wildmatch.WMOptions{
+		Pathname:     pathname,
+		CaseFold:     g.opts.CaseFold || pflags&flagFold != 0,
+		CaseFoldMask: mask,
+		MaxStarstar:  g.opts.MaxStarstar,
+	}
+}
+
+// foldMask builds the per-byte case-folding mask for text by consulting
+// CaseFoldFunc once per '/'-separated component. It returns nil when
+// CaseFoldFunc is unset.
+func (g *GitIgnore) foldMask(text string) []bool {
+	if g.opts.CaseFoldFunc == nil {
+		return nil
+	}
+
+	mask := make([]bool, len(text))
+	start := 0
+
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && text[i] != '/' {
+			continue
+		}
+
+		if g.opts.CaseFoldFunc(text[start:i]) {
+			for j := start; j < i; j++ {
+				mask[j] = true
+			}
+		}
+
+		start = i + 1
+	}
+
+	return mask
+}
+
+// tail returns the part of mask from index i on; a nil mask stays nil.
+func tail(mask []bool, i int) []bool {
+	if mask == nil {
+		return nil
+	}
+
+	return mask[i:]
+}
+
+// parsePattern compiles a single .gitignore pattern line. It reports false
+// for lines that compile to nothing (comments, blank lines).
 // It implements Git’s rules for comments, escapes, trimming of unescaped
 // trailing spaces, negation markers, and directory-only markers.
-func parsePattern(line string) *pattern {
+// The comment and negation markers are taken from opt.
+func parsePattern(line string, opt Options) (pattern, bool) {
 	original := line
+	comment, negation := opt.prefixes()
 
-	// Comments (unless escaped with '\#') and empty lines are inert.
-	if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "\\#")) {
-		return nil
+	// Comments (unless escaped with '\#'), empty and overlong lines are inert.
+	if line == "" || line[0] == comment || opt.tooLong(line) {
+		return pattern{}, false
 	}
 
-	p := &pattern{original: original}
+	p := pattern{original: original}
+
+	line = opt.normalize(line)
 
 	switch {
-	case strings.HasPrefix(line, "\\#"), strings.HasPrefix(line, "\\!"):
+	case len(line) > 1 && line[0] == '\\' && (line[1] == comment || line[1] == negation):
 		// Unescape escaped comment/negation prefix.
 		line = line[1:]
 
-	case strings.HasPrefix(line, "!"):
-		p.flags |= flagNegative
+	case line[0] == negation:
+		if !opt.IgnoreNegations {
+			p.flags |= flagNegative
+		} else if !opt.NegationsAsPatterns {
+			return pattern{}, false
+		}
 
 		line = line[1:]
 	}
@@ -307,36 +1171,155 @@ func parsePattern(line string) *pattern {
 	// Trim unescaped trailing spaces.
 	line = trimTrailingSpaces(line)
 	if line == "" {
-		return nil
+		return pattern{}, false
+	}
+
+	if opt.NormalizeSlashes {
+		// "./", "//" and the like name the root itself, which is never ignored.
+		if line = normalizeSlashes(line); line == "/" {
+			return pattern{}, false
+		}
 	}
 
 	// Trailing '/' means "directories only".
-	if strings.HasSuffix(line, "/") {
+	if line[len(line)-1] == '/' {
 		line = line[:len(line)-1]
 
 		p.flags |= flagDirOnly
 	}
 
+	// "**/*literal" matches the same names at every depth as "*literal";
+	// compile it as the basename form so it reaches the suffix fast path.
+	if rest, ok := strings.CutPrefix(line, "**/"); ok && len(rest) > 1 && rest[0] == '*' &&
+		!strings.Contains(rest, "/") && noWildcard(rest[1:]) {
+		line = rest
+	}
+
+	p.pattern = line
+	p.patternlen = len(line)
+
+	// Classify the remaining text in a single pass: leading literal bytes,
+	// '/' presence, and the number of '/' a matching path must contain. Only
+	// '**' can match '/' (a class never does), so that count is fixed unless
+	// the pattern contains '**' or a character class.
+	p.nowildcardlen = len(line)
+
+	slashes, hasSlash, variable := 0, false, false
+	endsWith := line != "" && line[0] == '*'
+
+	for i := range len(line) {
+		switch c := line[i]; {
+		case c == '/':
+			hasSlash = true
+
+			// A leading '/' only anchors the pattern.
+			if i > 0 {
+				slashes++
+			}
+		case isGlobSpecial(c):
+			p.nowildcardlen = min(p.nowildcardlen, i)
+
+			// Optimization: "*literal" pattern.
+			if i > 0 {
+				endsWith = false
+			}
+
+			if c == '[' || (c == '*' && i+1 < len(line) && line[i+1] == '*') {
+				variable = true
+			}
+		}
+	}
+
 	// No '/' means "basename-only".
-	if !strings.Contains(line, "/") {
+	if !hasSlash {
 		p.flags |= flagNoDir
 	}
 
-	// Count leading literal bytes.
-	p.nowildcardlen = simpleLength(line)
-	if p.nowildcardlen > len(line) {
-		p.nowildcardlen = len(line)
+	if endsWith {
+		p.flags |= flagEndsWith
 	}
 
-	// Optimization: "*literal" pattern.
-	if strings.HasPrefix(line, "*") && noWildcard(line[1:]) {
-		p.flags |= flagEndsWith
+	if dir, ok := strings.CutSuffix(line, "/**"); ok && strings.Trim(dir, "/") != "" && noWildcard(dir) {
+		p.flags |= flagContents
 	}
 
-	p.pattern = line
-	p.patternlen = len(line)
+	p.slashes = slashes
+	if variable {
+		p.slashes = -1
+	}
 
-	return p
+	p.minlen = wildmatch.MinLen(strings.TrimPrefix(line, "/"))
+
+	return p, true
+}
+
+// tooLong reports whether line exceeds the MaxPatternLength bound.
+func (o Options) tooLong(line string) bool {
+	switch {
+	case o.MaxPatternLength < 0:
+		return false
+	case o.MaxPatternLength == 0:
+		return len(line) > DefaultMaxPatternLength
+	default:
+		return len(line) > o.MaxPatternLength
+	}
+}
+
+// transform applies PathTransform to an input path, if set.
+func (o Options) transform(pathname string) string {
+	if o.PathTransform == nil {
+		return pathname
+	}
+
+	return o.PathTransform(pathname)
+}
+
+// input applies PathTransform and, with InferDirFromSlash, derives isDir
+// from a trailing '/'.
+func (o Options) input(pathname string, isDir bool) (string, bool) {
+	pathname = o.transform(pathname)
+
+	if o.InferDirFromSlash {
+		return splitDirSuffix(pathname)
+	}
+
+	return pathname, isDir
+}
+
+// keepDir returns pathname such that input keeps isDir for it: with
+// InferDirFromSlash, which ignores isDir, a directory gets back the trailing
+// '/' marking it. Callers that know the kind of a path for certain, such as
+// walkers, pass it through keepDir before handing it to Match or Ignored.
+func (o Options) keepDir(pathname string, isDir bool) string {
+	if o.InferDirFromSlash && isDir && !strings.HasSuffix(pathname, "/") {
+		return pathname + "/"
+	}
+
+	return pathname
+}
+
+// normalize returns s in Unicode NFC form when NormalizeUnicode is set.
+func (o Options) normalize(s string) string {
+	if !o.NormalizeUnicode {
+		return s
+	}
+
+	return norm.NFC.String(s)
+}
+
+// prefixes returns the configured comment and negation markers, applying defaults.
+func (o Options) prefixes() (comment, negation byte) {
+	comment, negation = '#', '!'
+
+	if o.CommentPrefix != 0 {
+		comment = o.CommentPrefix
+	}
+
+	if o.NegationPrefix != 0 {
+		negation = o.NegationPrefix
+	}
+
+	return comment, negation
 }
 
 // trimTrailingSpaces removes unescaped trailing space characters from s.
@@ -362,6 +1345,46 @@ func trimTrailingSpaces(s string) string {
 	return s
 }
 
+// normalizeSlashes collapses each run of unescaped '/' in s into one and
+// rewrites leading "./" components as the root anchor, for
+// Options.NormalizeSlashes: "./a//b/" becomes "/a/b/".
+func normalizeSlashes(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	slash := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '/' && slash {
+			continue
+		}
+
+		slash = c == '/'
+
+		b.WriteByte(c)
+
+		// An escaped byte is copied as is, so "\//" keeps both slashes.
+		if c == '\\' && i+1 < len(s) {
+			i++
+
+			b.WriteByte(s[i])
+		}
+	}
+
+	s = b.String()
+
+	for {
+		rest, ok := strings.CutPrefix(strings.TrimPrefix(s, "/"), "./")
+		if !ok {
+			return s
+		}
+
+		s = "/" + rest
+	}
+}
+
 // simpleLength returns the number of leading literal (non-glob) bytes in s.
 // Stops at the first meta character recognized by this matcher.
 func simpleLength(s string) int {
@@ -374,44 +1397,48 @@ func simpleLength(s string) int {
 	return len(s)
 }
 
-// parentExcludedWithPattern reports whether any ancestor is excluded and
-// returns the deciding pattern for that ancestor (if excluded).
-func (g *GitIgnore) parentExcludedWithPattern(pathname string) (bool, string) {
+// parentExcluded reports whether any ancestor is excluded by returning the
+// index of the deciding pattern for the outermost excluded ancestor along with
+// that ancestor, or -1 and "" if none is excluded.
+func (g *GitIgnore) parentExcluded(pathname string) (int, string) {
+	return g.parentExcludedIn(pathname, nil)
+}
+
+// parentExcludedIn is parentExcluded reading and recording the excluding
+// pattern index of each ancestor (-1 if not excluded) in seen when it is
+// non-nil, so paths sharing ancestors evaluate each of them once.
+func (g *GitIgnore) parentExcludedIn(pathname string, seen map[string]int) (int, string) {
 	if pathname == "." {
-		return false, ""
+		return -1, ""
 	}
 
-	parts := strings.Split(pathname, "/")
-
-	for i := 1; i < len(parts); i++ { // exclude the full path itself
-		ancestor := strings.Join(parts[:i], "/")
-		isExcluded := false
-		decidingPattern := ""
+	// Ancestors are the prefixes ending just before each '/'; the full path
+	// itself is excluded.
+	for end := range len(pathname) {
+		if pathname[end] != '/' {
+			continue
+		}
 
-		for j := len(g.patterns) - 1; j >= 0; j-- {
-			p := g.patterns[j]
+		ancestor := pathname[:end]
 
-			if !g.matchesPattern(p, ancestor, true) {
-				continue
+		decidingIndex, ok := seen[ancestor]
+		if !ok {
+			decidingIndex = g.lastMatch(ancestor, true)
+			if decidingIndex >= 0 && g.patterns[decidingIndex].flags&flagNegative != 0 {
+				decidingIndex = -1
 			}
 
-			if p.flags&flagNegative != 0 {
-				isExcluded = false
-				decidingPattern = ""
-			} else {
-				isExcluded = true
-				decidingPattern = p.original
+			if seen != nil {
+				seen[ancestor] = decidingIndex
 			}
-
-			break
 		}
 
-		if isExcluded {
-			return true, decidingPattern
+		if decidingIndex >= 0 {
+			return decidingIndex, ancestor
 		}
 	}
 
-	return false, ""
+	return -1, ""
 }
 
 // isGlobSpecial reports whether c is a glob meta-character recognized by this
@@ -2,6 +2,7 @@ package gitignore_test
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -31,6 +32,58 @@ func BenchmarkNew(b *testing.B) {
 			_ = gitignore.New(patterns...)
 		}
 	})
+
+	b.Run("10000_Mixed_Patterns", func(b *testing.B) {
+		patterns := append(generateSimplePatterns(5000), generateComplexPatterns(5000)...)
+
+		b.ResetTimer()
+
+		for b.Loop() {
+			_ = gitignore.New(patterns...)
+		}
+	})
+}
+
+// BenchmarkIntern builds a matcher from one template repeated many times, as
+// concatenated generated ignore files are, and reports the heap the matcher
+// keeps alive with and without Options.Intern.
+func BenchmarkIntern(b *testing.B) {
+	content := strings.Repeat(strings.Join(getRealWorldGitignore(), "\n")+"\n", 500)
+
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Intern_%v", intern), func(b *testing.B) {
+			opt := gitignore.Options{Intern: intern}
+
+			build := func() *gitignore.GitIgnore {
+				g, err := gitignore.NewFromReader(opt, strings.NewReader(content))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				return g
+			}
+
+			var before, after runtime.MemStats
+
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			g := build()
+
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			runtime.KeepAlive(g)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_ = build()
+			}
+
+			b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "retained-B")
+		})
+	}
 }
 
 func BenchmarkIgnored(b *testing.B) {
@@ -78,7 +131,90 @@ func BenchmarkIgnored(b *testing.B) {
 		})
 	})
 
-	// Scenario 3: Real-world simulation
+	// Scenario 3: Basename-only rule sets take the allocation-free fast path
+	b.Run("Basename_Only", func(b *testing.B) {
+		gi := gitignore.New("*.log", "node_modules", "build/", ".DS_Store", "*.py[cod]")
+		path := "src/app/core/services/api.service.ts"
+
+		b.ResetTimer()
+
+		for b.Loop() {
+			result = gi.Ignored(path, false)
+		}
+	})
+
+	// Scenario 4: Literal path rules are matched without allocating
+	b.Run("Literal_Paths", func(b *testing.B) {
+		gi := gitignore.New("/docs/build", "src/generated/schema.go", "config/local.yaml", "/.env")
+		path := "src/generated/nested/schema.go"
+
+		b.ResetTimer()
+
+		for b.Loop() {
+			result = gi.Ignored(path, false)
+		}
+	})
+
+	// Scenario 5: Nested globstars against a deep path stress backtracking
+	b.Run("Nested_Globstar", func(b *testing.B) {
+		gi := gitignore.New("**/a/**/b/**/c/**/d/**/e/**/f")
+		path := strings.Repeat("a/b/c/d/e/", 16) + "x"
+
+		b.ResetTimer()
+
+		for b.Loop() {
+			result = gi.Ignored(path, false)
+		}
+	})
+
+	// Scenario 6: Leading-globstar suffix rules share the "*literal" fast path
+	b.Run("Globstar_Suffix", func(b *testing.B) {
+		gi := gitignore.New("**/*.min.js", "**/*-test.go", "**/*.pb.go")
+		path := "web/static/vendor/jquery/dist/jquery.js"
+
+		b.ResetTimer()
+
+		for b.Loop() {
+			result = gi.Ignored(path, false)
+		}
+	})
+
+	// Scenario 7: CaseFold, folding every byte or matching pre-lowered data
+	b.Run("CaseFold", func(b *testing.B) {
+		gi := gitignore.NewOptions(gitignore.Options{CaseFold: true}, getRealWorldGitignore()...)
+		path := "Src/Components/Button/Index.TSX"
+
+		b.Run("PerByte", func(b *testing.B) {
+			for b.Loop() {
+				result = gi.Ignored(path, false)
+			}
+		})
+
+		b.Run("LowerFold", func(b *testing.B) {
+			lowered := gi.LowerFold()
+
+			for b.Loop() {
+				result = lowered.Ignored(gitignore.FoldPath(path), false)
+			}
+		})
+	})
+
+	// Scenario 8: Long basename patterns against short names, rejected by
+	// their minimum match length before wildmatch runs
+	b.Run("Long_Basename_Patterns", func(b *testing.B) {
+		patterns := make([]string, 200)
+		for i := range patterns {
+			patterns[i] = fmt.Sprintf("*[0-9a-f]-%d-??????????.[ch]", i)
+		}
+
+		gi := gitignore.New(patterns...)
+
+		for b.Loop() {
+			result = gi.Ignored("src/pkg/main.go", false)
+		}
+	})
+
+	// Scenario 9: Real-world simulation
 	b.Run("RealWorld_Simulation", func(b *testing.B) {
 		// A mix of paths to check against the real-world gitignore
 		paths := []string{
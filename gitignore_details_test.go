@@ -52,7 +52,10 @@ func TestGitDetails(t *testing.T) {
 						t.Fatal("no test cases found")
 					}
 
-					g := gitignore.New(strings.Split(spec.Gitignore, "\n")...)
+					g := gitignore.NewOptions(
+						gitignore.Options{CaseFold: spec.IgnoreCase},
+						strings.Split(spec.Gitignore, "\n")...,
+					)
 
 					// Process each individual test case
 					for _, tc := range spec.Cases {
@@ -59,15 +59,24 @@ var vocab = []string{
 	"**/.env",
 	".dockerignore",
 	"**/.gitkeep",
+
+	// --- Case folding ---
+	"*.LOG",
+	"Build/",
+	"[A-Z]*",
+	"[Z-a]",
+	"[[:upper:]]*.txt",
+	"\\B",
 }
 
 // FuzzGitIgnoreParity fuzzes random .gitignore contents + paths,
 // uses `git check-ignore` as the oracle, and asserts our matcher agrees.
 //
 // Git's exit code (0=ignored, 1=not ignored) becomes the expected value for the package under test.
+// When caseFold is set, Git runs with core.ignorecase=true and the matcher with Options.CaseFold.
 func FuzzGitIgnoreParity(f *testing.F) {
 	// A few useful seeds to hit tricky corners early.
-	seed := func(gi, p string, dir bool) { f.Add(gi, p, dir) }
+	seed := func(gi, p string, dir bool) { f.Add(gi, p, dir, false) }
 	// Parent exclusion / sandwich / contents-only:
 	seed("**/node_modules/**\n!**/node_modules/**/README.md\n", "a/b/node_modules/README.md", false)
 	seed("data/**\n!data/**/\n!data/**/*.txt\n", "data/data2/file2.txt", false)
@@ -93,7 +102,13 @@ func FuzzGitIgnoreParity(f *testing.F) {
 	seed("**/.env\n", "a/b/.env", false)
 	seed(".dockerignore\n", ".dockerignore", false)
 
-	f.Fuzz(func(t *testing.T, rawGitignore, rawPath string, isDir bool) {
+	// Case folding: class members and range endpoints are not folded, only the text is
+	f.Add("*.LOG\nBuild/\n", "build/App.log", false, true)
+	f.Add("[A]\n[Z-a]\n", "z", false, true)
+	f.Add("[[:lower:]]\n!\\B\n", "B", false, true)
+	f.Add("[A-Z]*/\n!docs/\n", "DOCS/x", false, true)
+
+	f.Fuzz(func(t *testing.T, rawGitignore, rawPath string, isDir, caseFold bool) {
 		gi := sanitizeGitignore(rawGitignore)
 
 		p := sanitizePath(rawPath)
@@ -103,8 +118,9 @@ func FuzzGitIgnoreParity(f *testing.F) {
 
 		// 1) Ask Git for ground truth via the existing helper.
 		spec := GitIgnore{
-			Name:      "fuzz",
-			Gitignore: gi,
+			Name:       "fuzz",
+			Gitignore:  gi,
+			IgnoreCase: caseFold,
 		}
 		c := Case{
 			Path:        p,
@@ -124,14 +140,15 @@ func FuzzGitIgnoreParity(f *testing.F) {
 		want := res.Actual
 
 		// 2) Run our implementation under test on the same inputs.
-		g := gitignore.New(strings.Split(gi, "\n")...)
+		g := gitignore.NewOptions(gitignore.Options{CaseFold: caseFold}, strings.Split(gi, "\n")...)
 		got := g.Ignored(p, isDir)
 
 		if got != want {
 			t.Fatalf(
-				"Ignored() check failed:\n  path: %v\n  dir: %v\n  patterns: %v\n  expected: %v\n  got: %v\n",
+				"Ignored() check failed:\n  path: %v\n  dir: %v\n  casefold: %v\n  patterns: %v\n  expected: %v\n  got: %v\n",
 				p,
 				isDir,
+				caseFold,
 				strings.Split(spec.Gitignore, "\n"),
 				boolToIgnored(want),
 				boolToIgnored(got),
@@ -289,3 +306,49 @@ func compactToPrintable(s string) string {
 
 	return strings.TrimSpace(string(out))
 }
+
+// FuzzWriteToRoundTrip builds a matcher from sanitized lines, serializes it
+// with WriteTo, re-parses the output with NewFromReader, and asserts both
+// matchers are Equal and agree on paths derived from the input.
+func FuzzWriteToRoundTrip(f *testing.F) {
+	f.Add("*.log\n# comment\n\n!keep.log\n", "a/keep.log", false)
+	f.Add("name\\ \\ \ntrailing  \n\\#literal\n\\!bang\n", "name  ", false)
+	f.Add("a/**/b/\n   lead\n\tab\n", "a/x/b", true)
+
+	f.Fuzz(func(t *testing.T, rawGitignore, rawPath string, caseFold bool) {
+		opts := gitignore.Options{CaseFold: caseFold}
+		g := gitignore.NewOptions(opts, strings.Split(sanitizeGitignore(rawGitignore), "\n")...)
+
+		var buf strings.Builder
+		if _, err := g.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() error: %v", err)
+		}
+
+		restored, err := gitignore.NewFromReader(opts, strings.NewReader(buf.String()))
+		if err != nil {
+			t.Fatalf("NewFromReader() error: %v", err)
+		}
+
+		if !restored.Equal(g) {
+			t.Fatalf("round trip changed the patterns:\n  before: %q\n  after:  %q", g.Patterns(), restored.Patterns())
+		}
+
+		// Check the path and each of its ancestors, as files and directories.
+		p := sanitizePath(rawPath)
+
+		for {
+			for _, isDir := range []bool{false, true} {
+				if got, want := restored.Ignored(p, isDir), g.Ignored(p, isDir); got != want {
+					t.Fatalf("Ignored(%q, %v) = %v after the round trip, %v before", p, isDir, got, want)
+				}
+			}
+
+			slash := strings.LastIndexByte(p, '/')
+			if slash < 0 {
+				break
+			}
+
+			p = p[:slash]
+		}
+	})
+}
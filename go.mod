@@ -5,4 +5,5 @@ go 1.25.0
 require (
 	github.com/bmatcuk/doublestar/v4 v4.9.1
 	github.com/goccy/go-yaml v1.18.0
+	golang.org/x/text v0.30.0
 )
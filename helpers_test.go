@@ -44,6 +44,8 @@ type GitIgnore struct {
 	Description string `yaml:"description"`
 	// Gitignore contains the raw gitignore patterns (newline-separated)
 	Gitignore string `yaml:"gitignore"`
+	// IgnoreCase runs the group with case folding (core.ignorecase=true for git)
+	IgnoreCase bool `yaml:"ignorecase"`
 	// Cases contains all test cases for this gitignore pattern set
 	Cases []Case `yaml:"cases"`
 }
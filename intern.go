@@ -0,0 +1,42 @@
+package gitignore
+
+import "strings"
+
+// interner maps each distinct pattern string to the single copy the matcher
+// keeps of it (see Options.Intern).
+type interner map[string]string
+
+// pattern makes the strings of p share storage with identical strings seen
+// before. A first occurrence of the original line is copied, so the matcher
+// never pins the buffer the line was sliced from, and the normalized pattern
+// is then taken from within that copy when it occurs there.
+func (in interner) pattern(p *pattern) {
+	p.original = in.string(p.original)
+
+	if s, ok := in[p.pattern]; ok {
+		p.pattern = s
+
+		return
+	}
+
+	if i := strings.Index(p.original, p.pattern); i >= 0 {
+		p.pattern = p.original[i : i+len(p.pattern)]
+		in[p.pattern] = p.pattern
+
+		return
+	}
+
+	p.pattern = in.string(p.pattern)
+}
+
+// string returns the kept copy of s, copying and keeping s if it is new.
+func (in interner) string(s string) string {
+	if kept, ok := in[s]; ok {
+		return kept
+	}
+
+	s = strings.Clone(s)
+	in[s] = s
+
+	return s
+}
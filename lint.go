@@ -0,0 +1,110 @@
+package gitignore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintKind names a kind of finding reported by Lint.
+type LintKind string
+
+const (
+	// LintContentsOnly flags "dir/**" without a pattern matching dir itself:
+	// "dir/**" matches everything inside dir but not the directory, so a
+	// negation can still re-include its contents, and the idiomatic way to
+	// ignore both is to add "dir/" alongside it.
+	LintContentsOnly LintKind = "ContentsOnly"
+	// LintDirSlashAmbiguity flags two patterns of the same sign that differ
+	// only by a trailing '/', such as "build" and "build/". The first matches
+	// files and directories, the second only directories, so one of them is
+	// redundant or the distinction was not intended. Related is the line of
+	// the earlier pattern. A pattern and its negation are not flagged.
+	LintDirSlashAmbiguity LintKind = "DirSlashAmbiguity"
+)
+
+// Lint reports lines that are valid Git syntax but likely do not do what the
+// author meant, as Warnings with Kind set. Unlike Validate it is about
+// intent, not portability, and its hints never affect matching.
+func Lint(opt Options, lines ...string) []Warning {
+	var (
+		warnings []Warning
+		patterns = make([]pattern, 0, len(lines))
+	)
+
+	for i, line := range lines {
+		if p, ok := parsePattern(line, opt); ok {
+			p.line = i + 1
+			patterns = append(patterns, p)
+		}
+	}
+
+	for _, p := range patterns {
+		dir := contentsOnlyDir(&p)
+		if dir == "" || coversDir(patterns, dir) {
+			continue
+		}
+
+		warnings = append(warnings, Warning{
+			Line: p.line,
+			Text: p.original,
+			Reason: fmt.Sprintf("matches the contents of %q but not the directory itself; add %q to ignore both",
+				dir, dir+"/"),
+			Kind: LintContentsOnly,
+		})
+	}
+
+	for i, p := range patterns {
+		for _, earlier := range patterns[:i] {
+			if !slashAmbiguous(&earlier, &p) {
+				continue
+			}
+
+			warnings = append(warnings, Warning{
+				Line: p.line,
+				Text: p.original,
+				Reason: fmt.Sprintf("differs from %q only by a trailing '/'; with it only directories match, "+
+					"without it files match too", earlier.original),
+				Kind:    LintDirSlashAmbiguity,
+				Related: earlier.line,
+			})
+		}
+	}
+
+	return warnings
+}
+
+// slashAmbiguous reports whether a and b have the same sign and pattern but
+// only one of them is directory-only.
+func slashAmbiguous(a, b *pattern) bool {
+	return a.pattern == b.pattern &&
+		a.flags&flagNegative == b.flags&flagNegative &&
+		a.flags&flagDirOnly != b.flags&flagDirOnly
+}
+
+// contentsOnlyDir returns dir for a positive "dir/**" pattern, or "".
+func contentsOnlyDir(p *pattern) string {
+	if p.flags&(flagNegative|flagDirOnly) != 0 {
+		return ""
+	}
+
+	dir, ok := strings.CutSuffix(p.pattern, "/**")
+	if !ok || strings.Trim(dir, "/") == "" {
+		return ""
+	}
+
+	return dir
+}
+
+// coversDir reports whether a positive pattern matches dir itself, comparing
+// the compiled text with any anchoring '/' removed.
+func coversDir(patterns []pattern, dir string) bool {
+	dir = strings.TrimPrefix(dir, "/")
+
+	for _, p := range patterns {
+		if p.flags&flagNegative == 0 && strings.TrimPrefix(p.pattern, "/") == dir {
+			return true
+		}
+	}
+
+	return false
+}
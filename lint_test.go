@@ -0,0 +1,79 @@
+package gitignore_test
+
+import (
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"vendor/**",
+		"build/**",
+		"build/",
+		"/out/**",
+		"out",
+		"!keep/**",
+		"**",
+		"/**",
+		"# docs/**",
+		"a/**/b",
+		"cache/**/",
+	}
+
+	warnings := gitignore.Lint(gitignore.Options{}, lines...)
+
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings %v, want one for vendor/**", len(warnings), warnings)
+	}
+
+	if w := warnings[0]; w.Line != 1 || w.Text != "vendor/**" {
+		t.Errorf("warning = %s, want line 1 for vendor/**", w)
+	}
+
+	if w := gitignore.Lint(gitignore.Options{}, "/tmp/**", "!tmp/"); len(w) != 1 {
+		t.Errorf("Lint() = %v, want a hint when the directory is only negated", w)
+	}
+}
+
+func TestLintDirSlashAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"build",
+		"*.log",
+		"build/",
+		"out/",
+		"!out", // a negation pair is intentional
+		"/dist",
+		"dist/", // anchoring differs, so the patterns differ
+		"!tmp",
+		"!tmp/",
+		"# build/",
+	}
+
+	var got []gitignore.Warning
+
+	for _, w := range gitignore.Lint(gitignore.Options{}, lines...) {
+		if w.Kind == gitignore.LintDirSlashAmbiguity {
+			got = append(got, w)
+		}
+	}
+
+	want := []struct{ line, related int }{{3, 1}, {9, 8}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings %v, want %d", len(got), got, len(want))
+	}
+
+	for i, w := range got {
+		if w.Line != want[i].line || w.Related != want[i].related || w.Text != lines[w.Line-1] {
+			t.Errorf("finding %d = %+v, want line %d related to line %d", i, w, want[i].line, want[i].related)
+		}
+	}
+
+	if w := gitignore.Lint(gitignore.Options{}, "vendor/**"); len(w) != 1 || w[0].Kind != gitignore.LintContentsOnly {
+		t.Errorf("Lint(vendor/**) = %+v, want one %s finding", w, gitignore.LintContentsOnly)
+	}
+}
@@ -0,0 +1,76 @@
+package gitignore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// NewReadDir builds a matcher from the .gitignore file in directory dir of fsys.
+// A missing .gitignore yields an empty matcher rather than an error; any other
+// read error is returned. Patterns are compiled as by Compile.
+func NewReadDir(opt Options, fsys fs.FS, dir string) (*GitIgnore, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewOptions(opt), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(opt, splitLines(data)...)
+}
+
+// NewFromReader builds a matcher from ignore-file contents read from r.
+// Read errors are returned wrapped; patterns are compiled as by Compile, so
+// in strict mode problems are reported as *ParseError and otherwise ignored.
+func NewFromReader(opt Options, r io.Reader) (*GitIgnore, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading ignore patterns: %w", err)
+	}
+
+	return Compile(opt, splitLines(data)...)
+}
+
+// LoadFile builds a matcher from the ignore file at name, like NewFromReader.
+// Unlike NewReadDir, a missing file is an error, which satisfies
+// errors.Is(err, fs.ErrNotExist).
+func LoadFile(opt Options, name string) (*GitIgnore, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("loading ignore file: %w", err)
+	}
+
+	return Compile(opt, splitLines(data)...)
+}
+
+// LoadFS builds a matcher from the ignore file at name in fsys, such as a
+// default ignore set shipped in an embed.FS, like LoadFile does for the
+// operating system's files. A missing file is an error here as well.
+func LoadFS(opt Options, fsys fs.FS, name string) (*GitIgnore, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("loading ignore file: %w", err)
+	}
+
+	return Compile(opt, splitLines(data)...)
+}
+
+// splitLines splits the contents of an ignore file into lines. Like Git, it
+// drops one '\r' before each line end, so files with CRLF line endings parse
+// exactly like their LF versions whatever core.autocrlf is set to.
+func splitLines(data []byte) []string {
+	lines := strings.Split(string(data), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines
+}
@@ -0,0 +1,172 @@
+package gitignore_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestNewReadDir(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		".gitignore":     {Data: []byte("*.log\n# comment\nbuild/\n")},
+		"sub/.gitignore": {Data: []byte("!keep.log\n")},
+		"empty/file":     {Data: []byte("x")},
+		"bad/.gitignore": {Mode: fs.ModeDir | 0o755}, // a directory named .gitignore cannot be read
+	}
+
+	g, err := gitignore.NewReadDir(gitignore.Options{}, fsys, ".")
+	if err != nil {
+		t.Fatalf("root: unexpected error: %v", err)
+	}
+
+	if got := g.Patterns(); len(got) != 2 || got[0] != "*.log" || got[1] != "build/" {
+		t.Errorf("root: Patterns() = %q", got)
+	}
+
+	sub, err := gitignore.NewReadDir(gitignore.Options{}, fsys, "sub")
+	if err != nil {
+		t.Fatalf("sub: unexpected error: %v", err)
+	}
+
+	if got := sub.Patterns(); len(got) != 1 || got[0] != "!keep.log" {
+		t.Errorf("sub: Patterns() = %q", got)
+	}
+
+	empty, err := gitignore.NewReadDir(gitignore.Options{}, fsys, "empty")
+	if err != nil {
+		t.Fatalf("missing .gitignore: unexpected error: %v", err)
+	}
+
+	if len(empty.Patterns()) != 0 {
+		t.Errorf("missing .gitignore: Patterns() = %q, want none", empty.Patterns())
+	}
+
+	if _, err := gitignore.NewReadDir(gitignore.Options{}, fsys, "bad"); err == nil {
+		t.Error("unreadable .gitignore: expected error")
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	t.Parallel()
+
+	g, err := gitignore.NewFromReader(gitignore.Options{}, strings.NewReader("*.log\n[abc\n"))
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %v", err)
+	}
+
+	if !g.Ignored("app.log", false) {
+		t.Error("lenient: app.log not ignored")
+	}
+
+	_, err = gitignore.NewFromReader(gitignore.Options{Strict: true}, strings.NewReader("*.log\n[abc\nfoo\\\n"))
+
+	var perr *gitignore.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("strict: error %v is not a *ParseError", err)
+	}
+
+	if perr.Line != 2 || perr.Text != "[abc" || perr.Reason == "" {
+		t.Errorf("strict: first ParseError = %+v, want line 2 for [abc", perr)
+	}
+
+	readErr := errors.New("boom")
+	if _, err := gitignore.NewFromReader(gitignore.Options{}, iotest.ErrReader(readErr)); !errors.Is(err, readErr) ||
+		errors.As(err, &perr) {
+		t.Errorf("read failure: error %v does not wrap the I/O error", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, ".gitignore")
+
+	if err := os.WriteFile(name, []byte("build/\n!build/keep\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gitignore.LoadFile(gitignore.Options{Strict: true}, name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := g.Patterns(); len(got) != 2 {
+		t.Errorf("Patterns() = %q, want 2 patterns", got)
+	}
+
+	if _, err := gitignore.LoadFile(gitignore.Options{}, filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: error %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"defaults/go.gitignore":  {Data: []byte("# Go defaults\r\n*.test\r\n/vendor/\r\n")},
+		"defaults/bad.gitignore": {Data: []byte("[abc\n")},
+	}
+
+	g, err := gitignore.LoadFS(gitignore.Options{}, fsys, "defaults/go.gitignore")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !g.Ignored("pkg/app.test", false) || !g.Ignored("vendor", true) {
+		t.Errorf("Patterns() = %q, want *.test and /vendor/ applied", g.Patterns())
+	}
+
+	if _, err := gitignore.LoadFS(gitignore.Options{}, fsys, "defaults/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: error %v, want fs.ErrNotExist", err)
+	}
+
+	var perr *gitignore.ParseError
+	if _, err := gitignore.LoadFS(gitignore.Options{Strict: true}, fsys, "defaults/bad.gitignore"); !errors.As(err, &perr) {
+		t.Errorf("strict: error %v, want a *ParseError", err)
+	}
+}
+
+func TestCRLF(t *testing.T) {
+	t.Parallel()
+
+	lf := "# comment\n*.log\nname\\ \n!keep.log\nbuild/  \n"
+	crlf := strings.ReplaceAll(lf, "\n", "\r\n")
+
+	fsys := fstest.MapFS{
+		"lf/.gitignore":   {Data: []byte(lf)},
+		"crlf/.gitignore": {Data: []byte(crlf)},
+	}
+
+	want, err := gitignore.NewReadDir(gitignore.Options{}, fsys, "lf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gitignore.NewReadDir(gitignore.Options{}, fsys, "crlf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.Equal(want) {
+		t.Errorf("CRLF patterns %q differ from LF patterns %q", got.Patterns(), want.Patterns())
+	}
+
+	if !got.Ignored("build", true) || !got.Ignored("name ", false) || got.Ignored("keep.log", false) {
+		t.Error("CRLF matcher does not behave like the LF one")
+	}
+
+	fromReader, err := gitignore.NewFromReader(gitignore.Options{}, strings.NewReader(crlf))
+	if err != nil || !fromReader.Equal(want) {
+		t.Errorf("NewFromReader(CRLF) = %q, %v; want the LF patterns", fromReader.Patterns(), err)
+	}
+}
@@ -0,0 +1,437 @@
+package gitignore_test
+
+import (
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestMatchReason(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/", "!build/keep.txt")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  gitignore.Match
+	}{
+		{path: "src/main.go", want: gitignore.Match{Reason: gitignore.ReasonNoMatch}},
+		{
+			path: "app.log",
+			want: gitignore.Match{Ignored: true, Pattern: "*.log", Reason: gitignore.ReasonIgnored, Line: 1},
+		},
+		{
+			path: "keep.log",
+			want: gitignore.Match{Pattern: "!keep.log", Reason: gitignore.ReasonNegated, Line: 2},
+		},
+		{
+			path:  "build",
+			isDir: true,
+			want:  gitignore.Match{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonIgnored, Line: 3},
+		},
+		{
+			path: "build/keep.txt",
+			want: gitignore.Match{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonParentExcluded, Line: 3},
+		},
+		{
+			path: "build/other.txt",
+			want: gitignore.Match{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonParentExcluded, Line: 3},
+		},
+	}
+
+	for _, tc := range tests {
+		if got := g.Match(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("Match(%q, %v) = %+v, want %+v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("build/", "*.log", "!keep.log")
+
+	paths := []string{"build/", "build", "src/app.log", "keep.log", "dir2/sub/", "build/out.bin"}
+
+	want := []gitignore.Match{
+		{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonIgnored, Line: 1},
+		{Reason: gitignore.ReasonNoMatch},
+		{Ignored: true, Pattern: "*.log", Reason: gitignore.ReasonIgnored, Line: 2},
+		{Pattern: "!keep.log", Reason: gitignore.ReasonNegated, Line: 3},
+		{Reason: gitignore.ReasonNoMatch},
+		{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonParentExcluded, Line: 1},
+	}
+
+	got := g.Classify(paths)
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Classify(%q) = %+v, want %+v", paths[i], got[i], want[i])
+		}
+
+		if ignored := g.IgnoredPath(paths[i]); ignored != want[i].Ignored {
+			t.Errorf("IgnoredPath(%q) = %v, want %v", paths[i], ignored, want[i].Ignored)
+		}
+	}
+}
+
+func TestMatchFull(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("vendor/", "*.log", "!keep.log")
+
+	tests := []struct {
+		path         string
+		isDir        bool
+		wantPattern  string
+		wantAncestor string
+	}{
+		{path: "a/vendor/lib/keep.log", wantPattern: "vendor/", wantAncestor: "a/vendor"},
+		{path: "vendor/x/y.go", wantPattern: "vendor/", wantAncestor: "vendor"},
+		{path: "vendor", isDir: true, wantPattern: "vendor/", wantAncestor: ""},
+		{path: "src/app.log", wantPattern: "*.log", wantAncestor: ""},
+		{path: "src/main.go", wantPattern: "", wantAncestor: ""},
+	}
+
+	for _, tc := range tests {
+		m, ancestor := g.MatchFull(tc.path, tc.isDir)
+		if m.Pattern != tc.wantPattern || ancestor != tc.wantAncestor {
+			t.Errorf("MatchFull(%q, %v) = (%q, %q), want (%q, %q)",
+				tc.path, tc.isDir, m.Pattern, ancestor, tc.wantPattern, tc.wantAncestor)
+		}
+	}
+}
+
+func TestTraversalPaths(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*", "!keep")
+
+	tests := []struct {
+		path string
+		want gitignore.Reason
+	}{
+		{path: "..", want: gitignore.ReasonNoMatch},
+		{path: "../a", want: gitignore.ReasonNoMatch},
+		{path: "../../a", want: gitignore.ReasonNoMatch},
+		{path: "a/../../b", want: gitignore.ReasonNoMatch},
+		{path: "a/../b", want: gitignore.ReasonIgnored},      // cleans to b
+		{path: "a/b/../../c", want: gitignore.ReasonIgnored}, // cleans to c
+		{path: "a/../keep", want: gitignore.ReasonNegated},   // cleans to keep
+	}
+
+	for _, tc := range tests {
+		m := g.Match(tc.path, false)
+		if m.Reason != tc.want {
+			t.Errorf("Match(%q).Reason = %v, want %v", tc.path, m.Reason, tc.want)
+		}
+
+		if got := g.Ignored(tc.path, false); got != m.Ignored {
+			t.Errorf("Ignored(%q) = %v, Match reports %v", tc.path, got, m.Ignored)
+		}
+	}
+}
+
+func TestMatchDirect(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "!build/keep.txt")
+
+	tests := []struct {
+		path   string
+		isDir  bool
+		direct gitignore.Reason
+		full   gitignore.Reason
+	}{
+		{path: "build/keep.txt", direct: gitignore.ReasonNegated, full: gitignore.ReasonParentExcluded},
+		{path: "build/other.txt", direct: gitignore.ReasonNoMatch, full: gitignore.ReasonParentExcluded},
+		{path: "build/app.log", direct: gitignore.ReasonIgnored, full: gitignore.ReasonIgnored},
+		{path: "build", isDir: true, direct: gitignore.ReasonIgnored, full: gitignore.ReasonIgnored},
+		{path: "src/main.go", direct: gitignore.ReasonNoMatch, full: gitignore.ReasonNoMatch},
+	}
+
+	for _, tc := range tests {
+		if got := g.MatchDirect(tc.path, tc.isDir).Reason; got != tc.direct {
+			t.Errorf("MatchDirect(%q).Reason = %v, want %v", tc.path, got, tc.direct)
+		}
+
+		if got := g.Match(tc.path, tc.isDir).Reason; got != tc.full {
+			t.Errorf("Match(%q).Reason = %v, want %v", tc.path, got, tc.full)
+		}
+	}
+}
+
+func TestIgnoredDirs(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"build/", "vendor/**", "!vendor/lib/", "logs", "!logs/keep", "/tmp/*/", "a/", "!a/b/"}
+
+	dirs := []string{
+		"build", "src/build", "src/build/deep", "vendor", "vendor/other", "vendor/lib", "vendor/lib/sub",
+		"logs", "logs/keep", "tmp", "tmp/a", "tmp/a/b", "src", "./src//main", ".", "..", "", "/abs",
+		"a", "a/b", "a/b/c", "a/x",
+	}
+
+	for _, opt := range []gitignore.Options{{}, {AllowReincludeUnderExcluded: true}} {
+		g := gitignore.NewOptions(opt, lines...)
+
+		got := g.IgnoredDirs(dirs)
+		if len(got) != len(dirs) {
+			t.Fatalf("got %d results, want %d", len(got), len(dirs))
+		}
+
+		for _, dir := range dirs {
+			if want := g.Ignored(dir, true); got[dir] != want {
+				t.Errorf("AllowReincludeUnderExcluded=%v: IgnoredDirs()[%q] = %v, Ignored reports %v",
+					opt.AllowReincludeUnderExcluded, dir, got[dir], want)
+			}
+		}
+	}
+}
+
+func TestReincluded(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/", "!build/keep.log")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "keep.log", want: true},
+		{path: "src/keep.log", want: true},
+		{path: "app.log", want: false},        // ignored
+		{path: "main.go", want: false},        // no rule matched
+		{path: "build/keep.log", want: false}, // negation blocked by the excluded parent
+	}
+
+	for _, tc := range tests {
+		if got := g.Reincluded(tc.path, false); got != tc.want {
+			t.Errorf("Reincluded(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestInertLines(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("", "   ", "# comment", "!", "!   ")
+	if got := g.Patterns(); len(got) != 0 {
+		t.Errorf("Patterns() = %q, want none", got)
+	}
+}
+
+func TestCandidatesFor(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("build/", "*.log", "!keep.log", "/build", "src/*/")
+
+	tests := []struct {
+		path    string
+		want    []string
+		dirOnly []bool
+	}{
+		{path: "build", want: []string{"build/", "/build"}, dirOnly: []bool{true, false}},
+		{path: "a/keep.log", want: []string{"*.log", "!keep.log"}, dirOnly: []bool{false, false}},
+		{path: "src/x", want: []string{"src/*/"}, dirOnly: []bool{true}},
+		{path: "main.go"},
+		{path: "."},
+		{path: "../build"},
+	}
+
+	for _, tc := range tests {
+		got := g.CandidatesFor(tc.path)
+		if len(got) != len(tc.want) {
+			t.Errorf("CandidatesFor(%q) = %v, want %q", tc.path, got, tc.want)
+
+			continue
+		}
+
+		for i, p := range got {
+			if p.String() != tc.want[i] || p.DirOnly() != tc.dirOnly[i] || g.Patterns()[p.Index] != p.String() {
+				t.Errorf("CandidatesFor(%q)[%d] = %q (dir-only %v, index %d), want %q (dir-only %v)",
+					tc.path, i, p, p.DirOnly(), p.Index, tc.want[i], tc.dirOnly[i])
+			}
+		}
+	}
+}
+
+func TestAppendCount(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log")
+
+	if n := g.Append("# comment", "", "   ", "build/", "!keep.log"); n != 2 {
+		t.Errorf("Append() = %d, want 2", n)
+	}
+
+	if n := g.Append("# only a comment"); n != 0 {
+		t.Errorf("Append() = %d, want 0", n)
+	}
+
+	want := []string{"*.log", "build/", "!keep.log"}
+	if got := g.Patterns(); !slices.Equal(got, want) {
+		t.Errorf("Patterns() = %q, want %q", got, want)
+	}
+}
+
+func TestHasGlobstar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lines []string
+		want  bool
+	}{
+		{lines: nil, want: false},
+		{lines: []string{"*.log", "build/", "/src/*.go"}, want: false},
+		{lines: []string{"*.log", "docs/**"}, want: true},
+		{lines: []string{"!a/**/b"}, want: true},
+		{lines: []string{"**/*.log"}, want: false}, // compiled as "*.log"
+		{lines: []string{"# **", "\\**"}, want: true},
+	}
+
+	for _, tc := range tests {
+		g := gitignore.New(tc.lines...)
+		if got := g.HasGlobstar(); got != tc.want {
+			t.Errorf("New(%q).HasGlobstar() = %v, want %v", tc.lines, got, tc.want)
+		}
+
+		if got := g.WithOptions(gitignore.Options{CaseFold: true}).HasGlobstar(); got != tc.want {
+			t.Errorf("WithOptions: HasGlobstar() = %v, want %v", got, tc.want)
+		}
+
+		if got := gitignore.FromCompiled(gitignore.Options{}, g.Export()).HasGlobstar(); got != tc.want {
+			t.Errorf("FromCompiled: HasGlobstar() = %v, want %v", got, tc.want)
+		}
+	}
+
+	g := gitignore.New("*.log")
+	g.Append("**/node_modules/")
+
+	if !g.HasGlobstar() {
+		t.Error("HasGlobstar() = false after appending a globstar pattern")
+	}
+}
+
+func TestCommonRootPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{lines: nil, want: ""},
+		{lines: []string{"/src/app/*.go", "src/app/build/"}, want: "src/app"},
+		{lines: []string{"/src/app/*.go", "src/api/*.go"}, want: "src"},
+		{lines: []string{"src/a", "src/ab"}, want: "src"},
+		{lines: []string{"/build"}, want: "build"},
+		{lines: []string{"docs/**", "docs/gen/", "!*.md"}, want: "docs"},
+		{lines: []string{"src/a*"}, want: "src"},
+		{lines: []string{"/src/*.go", "*.log"}, want: ""}, // a basename pattern applies anywhere
+		{lines: []string{"/src/*.go", "/lib/*.go"}, want: ""},
+		{lines: []string{"/*.go"}, want: ""},
+		{lines: []string{"src/[ab]/x"}, want: "src"},
+		{lines: []string{"!src/keep"}, want: ""},
+	}
+
+	for _, tc := range tests {
+		g := gitignore.New(tc.lines...)
+		if got := g.CommonRootPrefix(); got != tc.want {
+			t.Errorf("New(%q).CommonRootPrefix() = %q, want %q", tc.lines, got, tc.want)
+		}
+	}
+}
+
+func TestIgnoredUntracked(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/")
+
+	index := map[string]bool{"debug.log": true, "build/keep.txt": true}
+	tracked := func(p string) bool { return index[p] }
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "debug.log", want: false}, // tracked despite *.log
+		{path: "./debug.log", want: false},
+		{path: "app.log", want: true},
+		{path: "build/keep.txt", want: false}, // tracked below an ignored directory
+		{path: "build/out.bin", want: true},
+		{path: "main.go", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.IgnoredUntracked(tc.path, false, tracked); got != tc.want {
+			t.Errorf("IgnoredUntracked(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	if !g.IgnoredUntracked("debug.log", false, nil) {
+		t.Error("IgnoredUntracked(nil tracked) differs from Ignored")
+	}
+}
+
+func TestBackslashPatterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		warn    bool // a trailing unescaped '\' never matches
+	}{
+		{pattern: `\`, warn: true},
+		{pattern: `\\`},
+		{pattern: `a\`, warn: true},
+		{pattern: `\a`},
+		{pattern: `/\`, warn: true},
+		{pattern: `d/\`, warn: true},
+		{pattern: `!\`, warn: true},
+		{pattern: `*\`, warn: true},
+		{pattern: `x/\\`},
+	}
+
+	paths := []string{`\`, "a", `a\`, "d", `d/\`, `x/\`, "x", ""}
+
+	for _, tc := range tests {
+		if warned := len(gitignore.Validate(gitignore.Options{}, tc.pattern)) > 0; warned != tc.warn {
+			t.Errorf("Validate(%q) warned = %v, want %v", tc.pattern, warned, tc.warn)
+		}
+
+		for _, opt := range []gitignore.Options{{}, {CaseFold: true}} {
+			g := gitignore.NewOptions(opt, tc.pattern)
+			lowered := g.LowerFold()
+
+			_ = g.CommonRootPrefix()
+
+			for _, p := range g.CandidatesFor("a") {
+				_ = p.Describe()
+			}
+
+			for _, path := range paths {
+				for _, isDir := range []bool{false, true} {
+					m := g.Match(path, isDir)
+
+					if tc.warn && m.Ignored {
+						t.Errorf("%q matched %q, want no match", tc.pattern, path)
+					}
+
+					if got := lowered.Match(gitignore.FoldPath(path), isDir); got.Ignored != m.Ignored {
+						t.Errorf("LowerFold: %q against %q = %v, want %v", tc.pattern, path, got.Ignored, m.Ignored)
+					}
+
+					if got := g.IgnoredDir(path); isDir && got != m.Ignored {
+						t.Errorf("IgnoredDir(%q) = %v, Match reports %v", path, got, m.Ignored)
+					}
+
+					_ = g.MatchDirect(path, isDir)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package gitignore
+
+import (
+	"path"
+	"strings"
+)
+
+// Matcher is the read-only query interface implemented by GitIgnore and by the
+// views derived from it.
+type Matcher interface {
+	// Match returns a detailed match result, including the deciding pattern.
+	Match(pathname string, isDir bool) Match
+	// Ignored reports whether a relative path should be ignored.
+	Ignored(pathname string, isDir bool) bool
+}
+
+// RootedAt returns a view of g as if its patterns lived in a .gitignore inside
+// directory dir. The view takes repository-root-relative paths: paths below dir
+// are matched relative to dir, while dir itself and paths outside of it are
+// never ignored.
+func (g *GitIgnore) RootedAt(dir string) Matcher {
+	dir = path.Clean(g.opts.normalize(dir))
+	if dir == "." {
+		return g
+	}
+
+	return rootedMatcher{g: g, dir: dir}
+}
+
+// rootedMatcher implements RootedAt.
+type rootedMatcher struct {
+	// the matcher applied below dir
+	g *GitIgnore
+	// the normalized and cleaned directory the patterns are relative to
+	dir string
+}
+
+// Match matches pathname relative to the view's directory. PathTransform,
+// Options.InferDirFromSlash and Options.NormalizeUnicode apply to pathname as
+// given, before the directory is cut off, just as FromWorkingDir applies them
+// to the rebased path.
+func (r rootedMatcher) Match(pathname string, isDir bool) Match {
+	pathname, isDir = r.g.opts.input(pathname, isDir)
+	if pathname == "" || strings.HasPrefix(pathname, "/") {
+		return Match{}
+	}
+
+	rel, ok := strings.CutPrefix(path.Clean(r.g.opts.normalize(pathname)), r.dir+"/")
+	if !ok {
+		return Match{}
+	}
+
+	return r.g.decideInput(rel, isDir, nil).match
+}
+
+// Ignored reports whether pathname is ignored by the view.
+func (r rootedMatcher) Ignored(pathname string, isDir bool) bool {
+	return r.Match(pathname, isDir).Ignored
+}
+
+// FromWorkingDir returns a view of g for a tool running in the repository
+// subdirectory repoRelSubdir, taking paths relative to that directory as
+// git check-ignore does when run from it: "a.log" names repoRelSubdir/a.log,
+// "." names the directory itself and "../x" its sibling x. The paths are
+// rebased onto the repository root before matching, so g's patterns keep
+// their meaning. Absolute paths are never ignored, as the view does not know
+// where the repository lives.
+func (g *GitIgnore) FromWorkingDir(repoRelSubdir string) Matcher {
+	dir := path.Clean(repoRelSubdir)
+	if dir == "." {
+		return g
+	}
+
+	return workingDirMatcher{g: g, dir: dir}
+}
+
+// workingDirMatcher implements FromWorkingDir.
+type workingDirMatcher struct {
+	// the matcher taking repository-root-relative paths
+	g *GitIgnore
+	// the cleaned working directory paths are relative to
+	dir string
+}
+
+// Match matches pathname after rebasing it onto the repository root. The
+// joined path is cleaned by the underlying matcher, which keeps a trailing
+// '/' visible to Options.InferDirFromSlash.
+func (w workingDirMatcher) Match(pathname string, isDir bool) Match {
+	if pathname == "" || strings.HasPrefix(pathname, "/") {
+		return Match{}
+	}
+
+	return w.g.Match(w.dir+"/"+pathname, isDir)
+}
+
+// Ignored reports whether pathname is ignored by the view.
+func (w workingDirMatcher) Ignored(pathname string, isDir bool) bool {
+	return w.Match(pathname, isDir).Ignored
+}
+
+// Snapshot returns an immutable view of g's current patterns and options that
+// is safe for concurrent use without locking, even while g itself is later
+// appended to: the view shares the compiled patterns g has now and never sees
+// the ones appended after it was taken. A server hot-reloading its ignore
+// files can keep the current snapshot in an atomic.Value, have requests match
+// against whatever snapshot they load, and store a new snapshot after each
+// reload. The view has no cache (see WithCache).
+func (g *GitIgnore) Snapshot() Matcher {
+	return snapshot{g: g.clone()}
+}
+
+// snapshot implements Snapshot. It hides the clone behind Matcher so the view
+// cannot be appended to.
+type snapshot struct {
+	// the private clone the view reads
+	g *GitIgnore
+}
+
+// Match returns the detailed result of the patterns captured by the view.
+func (s snapshot) Match(pathname string, isDir bool) Match {
+	return s.g.Match(pathname, isDir)
+}
+
+// Ignored reports whether pathname is ignored by the patterns captured by the view.
+func (s snapshot) Ignored(pathname string, isDir bool) bool {
+	return s.g.Ignored(pathname, isDir)
+}
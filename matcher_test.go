@@ -0,0 +1,189 @@
+package gitignore_test
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestRootedAt(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("/build", "*.log", "tmp/")
+
+	m := g.RootedAt("pkg/a/")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "pkg/a/build", isDir: true, want: true},
+		{path: "pkg/a/x/build", isDir: true, want: false}, // rooted at pkg/a only
+		{path: "pkg/a/x/app.log", want: true},
+		{path: "pkg/a/tmp/file", want: true},
+		{path: "build", isDir: true, want: false}, // outside the directory
+		{path: "app.log", want: false},
+		{path: "pkg/ab/app.log", want: false}, // sibling with a shared prefix
+		{path: "pkg/a", isDir: true, want: false},
+		{path: "./pkg/a/../a/app.log", want: true},
+	}
+
+	for _, tc := range tests {
+		if got := m.Ignored(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+
+	if got := g.RootedAt("."); got != gitignore.Matcher(g) {
+		t.Error("RootedAt(\".\") should return the matcher itself")
+	}
+}
+
+func TestRootedAtInput(t *testing.T) {
+	t.Parallel()
+
+	opts := gitignore.Options{
+		InferDirFromSlash: true,
+		PathTransform:     func(p string) string { return strings.ReplaceAll(p, "\\", "/") },
+	}
+
+	g := gitignore.NewOptions(opts, "build/", "*.log")
+
+	rooted, working := g.RootedAt("sub"), g.FromWorkingDir("sub")
+
+	// Both views transform the path and infer from its trailing '/' before
+	// anything else, so they agree on the paths below sub.
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "build/", want: true},
+		{path: "build\\", want: true},
+		{path: "x//build//", want: true},
+		{path: "build", isDir: true, want: false},
+		{path: "x\\app.log", want: true},
+		{path: "app.log/", want: true},
+	}
+
+	for _, tc := range tests {
+		if got := rooted.Ignored("sub/"+tc.path, tc.isDir); got != tc.want {
+			t.Errorf("RootedAt: Ignored(%q, %v) = %v, want %v", "sub/"+tc.path, tc.isDir, got, tc.want)
+		}
+
+		if got := rooted.Ignored("sub\\"+tc.path, tc.isDir); got != tc.want {
+			t.Errorf("RootedAt: Ignored(%q, %v) = %v, want %v", "sub\\"+tc.path, tc.isDir, got, tc.want)
+		}
+
+		if got := working.Ignored(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("FromWorkingDir: Ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestRootedAtNormalizeUnicode(t *testing.T) {
+	t.Parallel()
+
+	const composed, decomposed = "caf\u00e9", "cafe\u0301"
+
+	g := gitignore.NewOptions(gitignore.Options{NormalizeUnicode: true}, "*.log")
+
+	// Either spelling of the directory, in the view or in the path, cuts.
+	for _, dir := range []string{composed, decomposed} {
+		m := g.RootedAt(dir)
+
+		for _, p := range []string{composed + "/app.log", decomposed + "/app.log"} {
+			if !m.Ignored(p, false) {
+				t.Errorf("RootedAt(%+q).Ignored(%+q) = false, want true", dir, p)
+			}
+		}
+	}
+}
+
+func TestFromWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.NewOptions(gitignore.Options{InferDirFromSlash: true}, "/top", "sub/out/", "*.log", "!/sub/keep.log")
+
+	m := g.FromWorkingDir("./sub/")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "a.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "out/", want: true}, // the trailing '/' survives rebasing
+		{path: "out", isDir: true, want: false},
+		{path: "../top", want: true},
+		{path: "top", want: false}, // "/top" is relative to the repository root
+		{path: "../sub/keep.log", want: false},
+		{path: "./x/../a.log", want: true},
+		{path: "../../top", want: false}, // outside the repository
+		{path: "/top", want: false},
+		{path: "", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := m.Ignored(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+
+	if got := g.FromWorkingDir("."); got != gitignore.Matcher(g) {
+		t.Error("FromWorkingDir(\".\") should return the matcher itself")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/")
+
+	var current atomic.Value
+
+	current.Store(g.Snapshot())
+
+	first, ok := current.Load().(gitignore.Matcher)
+	if !ok {
+		t.Fatal("stored snapshot is not a Matcher")
+	}
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Go(func() {
+			for range 1000 {
+				if !first.Ignored("app.log", false) || !first.Ignored("keep.log", false) {
+					t.Error("snapshot changed while the matcher was appended to")
+
+					return
+				}
+			}
+		})
+	}
+
+	// Reload: append to the original and publish a new snapshot while the
+	// readers above still use the first one.
+	for range 100 {
+		g.Append("!keep.log", "keep.log")
+	}
+
+	g.Append("!keep.log")
+	current.Store(g.Snapshot())
+
+	wg.Wait()
+
+	if !first.Ignored("keep.log", false) || !first.Ignored("build", true) {
+		t.Error("first snapshot lost its patterns")
+	}
+
+	if m, _ := current.Load().(gitignore.Matcher); m.Ignored("keep.log", false) {
+		t.Error("reloaded snapshot does not see the appended negation")
+	}
+}
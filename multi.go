@@ -0,0 +1,45 @@
+package gitignore
+
+// MatchMany evaluates one path against several independent matchers and returns
+// one result per matcher, in the same order. A nil matcher never ignores.
+func MatchMany(pathname string, isDir bool, matchers ...*GitIgnore) []Match {
+	out := make([]Match, len(matchers))
+
+	for i, g := range matchers {
+		if g == nil {
+			continue
+		}
+
+		out[i] = g.Match(pathname, isDir)
+	}
+
+	return out
+}
+
+// IgnoredByAny reports whether at least one of the matchers, evaluated on its
+// own, ignores the path. Unlike layering the patterns, a negation in one
+// matcher never rescues a path another matcher ignores. A nil matcher never
+// ignores.
+func IgnoredByAny(pathname string, isDir bool, matchers ...*GitIgnore) bool {
+	for _, g := range matchers {
+		if g != nil && g.Ignored(pathname, isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IgnoredByAll reports whether every one of the matchers, evaluated on its
+// own, ignores the path, such as when auditing whether a global ignore list
+// alone already covers what a repository ignores. It is false when no
+// matchers are given, and a nil matcher never ignores.
+func IgnoredByAll(pathname string, isDir bool, matchers ...*GitIgnore) bool {
+	for _, g := range matchers {
+		if g == nil || !g.Ignored(pathname, isDir) {
+			return false
+		}
+	}
+
+	return len(matchers) > 0
+}
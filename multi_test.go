@@ -0,0 +1,67 @@
+package gitignore_test
+
+import (
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestMatchMany(t *testing.T) {
+	t.Parallel()
+
+	logs := gitignore.New("*.log")
+	build := gitignore.New("build/")
+	rescue := gitignore.New("*.log", "!keep.log")
+
+	got := gitignore.MatchMany("build/keep.log", false, logs, build, rescue, nil)
+
+	want := []gitignore.Match{
+		{Ignored: true, Pattern: "*.log", Reason: gitignore.ReasonIgnored, Line: 1},
+		{Ignored: true, Pattern: "build/", Reason: gitignore.ReasonParentExcluded, Line: 1},
+		{Ignored: false, Pattern: "!keep.log", Reason: gitignore.ReasonNegated, Line: 2},
+		{},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("matcher %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIgnoredByAnyAll(t *testing.T) {
+	t.Parallel()
+
+	global := gitignore.New("*.log", ".DS_Store")
+	repo := gitignore.New("*.log", "!keep.log", "build/")
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		matchers []*gitignore.GitIgnore
+		wantAny  bool
+		wantAll  bool
+	}{
+		{path: "app.log", matchers: []*gitignore.GitIgnore{global, repo}, wantAny: true, wantAll: true},
+		// The repository's negation does not rescue what the global list ignores.
+		{path: "keep.log", matchers: []*gitignore.GitIgnore{global, repo}, wantAny: true, wantAll: false},
+		{path: "build", isDir: true, matchers: []*gitignore.GitIgnore{global, repo}, wantAny: true, wantAll: false},
+		{path: "main.go", matchers: []*gitignore.GitIgnore{global, repo}, wantAny: false, wantAll: false},
+		{path: "app.log", matchers: []*gitignore.GitIgnore{global, nil}, wantAny: true, wantAll: false},
+		{path: "app.log", matchers: nil, wantAny: false, wantAll: false},
+	}
+
+	for _, tc := range tests {
+		if got := gitignore.IgnoredByAny(tc.path, tc.isDir, tc.matchers...); got != tc.wantAny {
+			t.Errorf("IgnoredByAny(%q) with %d matchers = %v, want %v", tc.path, len(tc.matchers), got, tc.wantAny)
+		}
+
+		if got := gitignore.IgnoredByAll(tc.path, tc.isDir, tc.matchers...); got != tc.wantAll {
+			t.Errorf("IgnoredByAll(%q) with %d matchers = %v, want %v", tc.path, len(tc.matchers), got, tc.wantAll)
+		}
+	}
+}
@@ -0,0 +1,597 @@
+package gitignore_test
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unsafe"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestCaseFold(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.NewOptions(gitignore.Options{CaseFold: true}, "Makefile", "docs/readme.md", "/Build", "*.log")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "makefile", want: true},
+		{path: "sub/MAKEFILE", want: true},
+		{path: "DOCS/README.MD", want: true},
+		{path: "build", isDir: true, want: true},
+		{path: "APP.LOG", want: true},
+		{path: "readme.md", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.Ignored(tc.path, tc.isDir); got != tc.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestCaseFoldFunc(t *testing.T) {
+	t.Parallel()
+
+	// Fold every component except one literally named "Makefile".
+	opts := gitignore.Options{
+		CaseFoldFunc: func(component string) bool { return component != "Makefile" },
+	}
+
+	g := gitignore.NewOptions(opts, "*.log", "makefile", "docs/readme.md")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "APP.LOG", want: true},
+		{path: "Makefile", want: false},
+		{path: "sub/MAKEFILE", want: true},
+		{path: "DOCS/README.md", want: true},
+		{path: "docs/Makefile", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.Ignored(tc.path, false); got != tc.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	// A function that never folds changes nothing, even where the literal
+	// prefix before a "**" is stripped.
+	lines := []string{"b**/?", "a**/b", "/Docs/*.md"}
+	never := gitignore.NewOptions(gitignore.Options{CaseFoldFunc: func(string) bool { return false }}, lines...)
+	plain := gitignore.New(lines...)
+
+	for _, p := range []string{"ba/ab", "BA/ab", "a/c/ba/b", "Docs/x.md", "docs/x.md"} {
+		if got, want := never.Ignored(p, true), plain.Ignored(p, true); got != want {
+			t.Errorf("never folding: Ignored(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestCustomPrefixes(t *testing.T) {
+	t.Parallel()
+
+	opts := gitignore.Options{CommentPrefix: ';', NegationPrefix: '-'}
+
+	g := gitignore.NewOptions(opts,
+		"; a comment",
+		"\\;literal",
+		"#hash",
+		"*.log",
+		"-keep.log",
+		"\\-dash",
+		"!bang",
+	)
+
+	// The ';' line is dropped as a comment; only the '-' line is negated.
+	want := []string{"\\;literal", "#hash", "*.log", "-keep.log", "\\-dash", "!bang"}
+	if got := g.Patterns(); !slices.Equal(got, want) {
+		t.Fatalf("Patterns() = %q, want %q", got, want)
+	}
+
+	g.Each(func(i int, p gitignore.Pattern) bool {
+		if got := p.Negated(); got != (p.String() == "-keep.log") {
+			t.Errorf("pattern %d %q: Negated() = %v", i, p, got)
+		}
+
+		return true
+	})
+
+	if m := g.Match("keep.log", false); m.Reason != gitignore.ReasonNegated || m.Pattern != "-keep.log" {
+		t.Errorf("Match(%q) = %+v, want re-included by %q", "keep.log", m, "-keep.log")
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: ";literal", want: true},
+		{path: "#hash", want: true},
+		{path: "a.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "-dash", want: true},
+		{path: "!bang", want: true},
+		{path: "bang", want: false},
+		{path: "; a comment", want: false},
+		{path: "a comment", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.Ignored(tc.path, false); got != tc.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeUnicode(t *testing.T) {
+	t.Parallel()
+
+	const (
+		nfc = "caf\u00e9"  // é as a single code point
+		nfd = "cafe\u0301" // e followed by a combining acute accent
+	)
+
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+	}{
+		{name: "NFC pattern, NFD path", pattern: nfc, path: "docs/" + nfd},
+		{name: "NFD pattern, NFC path", pattern: nfd, path: "docs/" + nfc},
+		{name: "rooted glob", pattern: "/docs/" + nfc + "*.md", path: "docs/" + nfd + "-notes.md"},
+	}
+
+	for _, tc := range tests {
+		if gitignore.New(tc.pattern).Ignored(tc.path, false) {
+			t.Errorf("%s: matched without normalization", tc.name)
+		}
+
+		g := gitignore.NewOptions(gitignore.Options{NormalizeUnicode: true}, tc.pattern)
+		if !g.Ignored(tc.path, false) {
+			t.Errorf("%s: Ignored(%q) = false, want true", tc.name, tc.path)
+		}
+
+		if m := g.Match(tc.path, false); m.Pattern != tc.pattern {
+			t.Errorf("%s: Match().Pattern = %q, want the original %q", tc.name, m.Pattern, tc.pattern)
+		}
+	}
+}
+
+func TestAllowReincludeUnderExcluded(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"build/", "!build/keep.txt", "logs/", "!*.keep"}
+
+	git := gitignore.New(lines...)
+	lenient := gitignore.NewOptions(gitignore.Options{AllowReincludeUnderExcluded: true}, lines...)
+
+	tests := []struct {
+		path       string
+		isDir      bool
+		gitIgnored bool
+		lenIgnored bool
+	}{
+		{path: "build/keep.txt", gitIgnored: true, lenIgnored: false},
+		{path: "build/other.txt", gitIgnored: true, lenIgnored: true},
+		{path: "build", isDir: true, gitIgnored: true, lenIgnored: true},
+		{path: "logs/a.keep", gitIgnored: true, lenIgnored: false},
+		{path: "src/a.keep", gitIgnored: false, lenIgnored: false},
+	}
+
+	for _, tc := range tests {
+		if got := git.Ignored(tc.path, tc.isDir); got != tc.gitIgnored {
+			t.Errorf("default: Ignored(%q) = %v, want %v", tc.path, got, tc.gitIgnored)
+		}
+
+		if got := lenient.Ignored(tc.path, tc.isDir); got != tc.lenIgnored {
+			t.Errorf("lenient: Ignored(%q) = %v, want %v", tc.path, got, tc.lenIgnored)
+		}
+	}
+
+	// Excluded directories stay walkable when a negation can reach below them.
+	if !git.ShouldSkipDir("build") || lenient.ShouldSkipDir("build") {
+		t.Error("ShouldSkipDir(build): want true by default and false when re-including")
+	}
+
+	onlyBuild := gitignore.NewOptions(gitignore.Options{AllowReincludeUnderExcluded: true}, "build/", "dist/", "!build/keep.txt")
+	if !onlyBuild.ShouldSkipDir("dist") {
+		t.Error("ShouldSkipDir(dist): no negation reaches it, want true")
+	}
+}
+
+func TestPathTransform(t *testing.T) {
+	t.Parallel()
+
+	opts := gitignore.Options{
+		PathTransform: func(p string) string { return strings.ReplaceAll(p, "\\", "/") },
+	}
+
+	g := gitignore.NewOptions(opts, "build/", "*.log", "!keep\\.log")
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "build\\out.bin", want: true},
+		{path: "src\\app.log", want: true},
+		{path: ".\\src\\\\main.go", want: false},
+		{path: "\\abs\\app.log", want: false}, // absolute once transformed
+	}
+
+	for _, tc := range tests {
+		if got := g.Ignored(tc.path, false); got != tc.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	// Patterns are not transformed: the escaped '.' stays a literal negation.
+	if g.Patterns()[2] != "!keep\\.log" || g.Ignored("keep.log", false) {
+		t.Error("PathTransform must not rewrite patterns")
+	}
+
+	// The basename fast path applies the transform as well.
+	lower := gitignore.NewOptions(gitignore.Options{PathTransform: strings.ToLower}, "*.log")
+	if !lower.Ignored("APP.LOG", false) {
+		t.Error("fast path: PathTransform not applied")
+	}
+}
+
+func TestInferDirFromSlash(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"build/", "*.log", "!keep.log"}
+	opts := gitignore.Options{InferDirFromSlash: true}
+
+	matchers := map[string]*gitignore.GitIgnore{
+		"mixed":     gitignore.NewOptions(opts, lines...),
+		"basenames": gitignore.NewOptions(opts, "build/", "*.log"),
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "build/", isDir: false, want: true}, // the slash wins over isDir
+		{path: "build", isDir: true, want: false},  // no slash, so a file
+		{path: "src/build//", isDir: false, want: true},
+		{path: "build/out.bin", isDir: false, want: true},
+		{path: "app.log", isDir: true, want: true},
+	}
+
+	for name, g := range matchers {
+		for _, tc := range tests {
+			if got := g.Ignored(tc.path, tc.isDir); got != tc.want {
+				t.Errorf("%s: Ignored(%q, %v) = %v, want %v", name, tc.path, tc.isDir, got, tc.want)
+			}
+
+			if got := g.Match(tc.path, tc.isDir).Ignored; got != tc.want {
+				t.Errorf("%s: Match(%q, %v).Ignored = %v, want %v", name, tc.path, tc.isDir, got, tc.want)
+			}
+		}
+	}
+
+	// The slash is inferred after PathTransform.
+	opts.PathTransform = func(p string) string { return strings.ReplaceAll(p, "\\", "/") }
+	if g := gitignore.NewOptions(opts, lines...); !g.Ignored("build\\", false) {
+		t.Error("InferDirFromSlash: trailing separator produced by PathTransform not honored")
+	}
+}
+
+func TestInferDirFromSlashKnownKind(t *testing.T) {
+	t.Parallel()
+
+	opts := gitignore.Options{InferDirFromSlash: true}
+
+	// Methods that know the kind of a path keep directories as such, on both
+	// the basename fast path and the general one.
+	matchers := map[string]*gitignore.GitIgnore{
+		"basenames": gitignore.NewOptions(opts, "build/"),
+		"mixed":     gitignore.NewOptions(opts, "build/", "/docs/*.tmp", "!build/keep"),
+	}
+
+	fsys := fstest.MapFS{
+		"build/out.bin": {Data: []byte("x")},
+		"src/main.go":   {Data: []byte("x")},
+	}
+
+	for name, g := range matchers {
+		if !g.IgnoredPath("build/") {
+			t.Errorf("%s: IgnoredPath(%q) = false, want true", name, "build/")
+		}
+
+		if m := g.Classify([]string{"build/"}); !m[0].Ignored {
+			t.Errorf("%s: Classify(%q) not ignored", name, "build/")
+		}
+
+		if m := g.MatchBatch([]string{"build"}, func(string) bool { return true }); !m[0].Ignored {
+			t.Errorf("%s: MatchBatch(%q) as a directory not ignored", name, "build")
+		}
+
+		if !g.IgnoredDir("build") || !g.IgnoredDirs([]string{"build"})["build"] {
+			t.Errorf("%s: IgnoredDir and IgnoredDirs should ignore %q", name, "build")
+		}
+
+		if !g.ShouldSkipDir("build") || g.DirMayContainIncluded("build") {
+			t.Errorf("%s: %q should be pruned", name, "build")
+		}
+
+		if file, dir := g.IgnoredEither("build"); file || !dir {
+			t.Errorf("%s: IgnoredEither(%q) = (%v, %v), want (false, true)", name, "build", file, dir)
+		}
+
+		if file, dir := g.IgnoredEither("build/"); !file || !dir {
+			t.Errorf("%s: IgnoredEither(%q) = (%v, %v), want (true, true)", name, "build/", file, dir)
+		}
+
+		if !g.RootedAt("sub").Ignored("sub/build/", false) {
+			t.Errorf("%s: RootedAt(%q).Ignored(%q, false) = false, want true", name, "sub", "sub/build/")
+		}
+
+		var walked []string
+
+		err := g.Walk(fsys, ".", gitignore.WalkOptions{}, func(name string, _ fs.DirEntry, err error) error {
+			walked = append(walked, name)
+
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if slices.Contains(walked, "build") || slices.Contains(walked, "build/out.bin") {
+			t.Errorf("%s: Walk visited %q, want build pruned", name, walked)
+		}
+
+		ignored, kept, err := g.ClassifyFS(fsys, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(ignored, []string{"build"}) || !slices.Equal(kept, []string{"src", "src/main.go"}) {
+			t.Errorf("%s: ClassifyFS = %q, %q; want build pruned", name, ignored, kept)
+		}
+
+		entries, err := fs.ReadDir(gitignore.FilteredFS(fsys, g), ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Name() != "src" {
+			t.Errorf("%s: FilteredFS lists %d root entries, want only src", name, len(entries))
+		}
+
+		entries, err = fs.ReadDir(fsys, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if m := g.MarkEntries(".", entries); !m[0].Ignored || m[1].Ignored {
+			t.Errorf("%s: MarkEntries(build, src) = (%v, %v), want (true, false)", name, m[0].Ignored, m[1].Ignored)
+		}
+
+		if reached, err := g.PatternReachesAnything(0, fsys, "."); err != nil || !reached {
+			t.Errorf("%s: PatternReachesAnything(0) = %v, %v; want true", name, reached, err)
+		}
+	}
+
+	if c := matchers["mixed"].Conflicts(); len(c) == 0 {
+		t.Error("Conflicts: the negation below the excluded build/ not reported")
+	}
+}
+
+func TestMaxPatternLength(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 20) + "*"
+
+	tests := []struct {
+		max  int
+		want bool
+	}{
+		{max: 0, want: true}, // the default bound is generous
+		{max: 10, want: false},
+		{max: 21, want: true},
+		{max: -1, want: true},
+	}
+
+	for _, tc := range tests {
+		opts := gitignore.Options{MaxPatternLength: tc.max}
+
+		g := gitignore.NewOptions(opts, "*.log", long)
+		if got := g.Ignored(strings.Repeat("a", 25), false); got != tc.want {
+			t.Errorf("MaxPatternLength %d: Ignored() = %v, want %v", tc.max, got, tc.want)
+		}
+
+		if warned := len(gitignore.Validate(opts, "*.log", long)) > 0; warned == tc.want {
+			t.Errorf("MaxPatternLength %d: Validate() warned = %v", tc.max, warned)
+		}
+	}
+
+	huge := strings.Repeat("*", gitignore.DefaultMaxPatternLength+1)
+	if g := gitignore.New(huge); len(g.Patterns()) != 0 {
+		t.Error("a pattern over DefaultMaxPatternLength was compiled")
+	}
+}
+
+func TestMaxStarstar(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"a/**/b/**/c", "*.log"}
+
+	tests := []struct {
+		limit int
+		path  string
+		want  bool
+	}{
+		{limit: 0, path: "a/x/b/y/c", want: true},
+		{limit: 2, path: "a/x/b/y/c", want: true},
+		{limit: 1, path: "a/x/b/y/c", want: false},
+		{limit: 1, path: "src/app.log", want: true}, // the "*literal" fast path has no '**'
+	}
+
+	for _, tc := range tests {
+		g := gitignore.NewOptions(gitignore.Options{MaxStarstar: tc.limit}, lines...)
+		if got := g.Ignored(tc.path, false); got != tc.want {
+			t.Errorf("MaxStarstar %d: Ignored(%q) = %v, want %v", tc.limit, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestIntern(t *testing.T) {
+	t.Parallel()
+
+	template := strings.Join([]string{"*.log", "build/", "!keep.log", "/dist", "docs/**"}, "\n")
+	content := strings.Repeat(template+"\n", 50)
+
+	plain, err := gitignore.NewFromReader(gitignore.Options{}, strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interned, err := gitignore.NewFromReader(gitignore.Options{Intern: true}, strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interned.Append("*.log", "!keep.log")
+
+	got, want := interned.Patterns(), append(plain.Patterns(), "*.log", "!keep.log")
+	if !slices.Equal(got, want) {
+		t.Fatalf("Patterns() = %q, want %q", got, want)
+	}
+
+	first := make(map[string]*byte)
+
+	for i, p := range got {
+		data := unsafe.StringData(p)
+		if kept, ok := first[p]; !ok {
+			first[p] = data
+		} else if kept != data {
+			t.Errorf("pattern %d %q does not share storage with its first occurrence", i, p)
+		}
+	}
+
+	for _, path := range []string{"app.log", "keep.log", "build/x", "dist", "sub/dist", "docs/a/b", "main.go"} {
+		if got, want := interned.Match(path, false), plain.Match(path, false); got.Ignored != want.Ignored ||
+			got.Pattern != want.Pattern {
+			t.Errorf("Match(%q) = %+v with Intern, %+v without", path, got, want)
+		}
+	}
+}
+
+func TestNormalizeSlashes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern   string
+		canonical string
+	}{
+		{pattern: "./foo", canonical: "/foo"},
+		{pattern: "././foo", canonical: "/foo"},
+		{pattern: "!./foo", canonical: "!/foo"},
+		{pattern: "foo//bar", canonical: "foo/bar"},
+		{pattern: "//foo///bar//", canonical: "/foo/bar/"},
+		{pattern: "./build//", canonical: "/build/"},
+		{pattern: "d//*.txt", canonical: "d/*.txt"},
+		{pattern: `a\//b`, canonical: `a\//b`}, // the escaped '/' is kept apart
+		{pattern: "a/./b", canonical: "a/./b"}, // only leading "./" is rewritten
+	}
+
+	paths := []string{"foo", "sub/foo", "foo/bar", "foo/bar/x", "build", "build/out", "d/e.txt", "a/b", "a//b", "x"}
+
+	opts := gitignore.Options{NormalizeSlashes: true}
+
+	for _, tc := range tests {
+		// A negation needs something to re-include.
+		var base []string
+		if tc.pattern[0] == '!' {
+			base = []string{"*"}
+		}
+
+		g := gitignore.NewOptions(opts, append(slices.Clone(base), tc.pattern)...)
+		want := gitignore.New(append(slices.Clone(base), tc.canonical)...)
+
+		for _, p := range paths {
+			for _, isDir := range []bool{false, true} {
+				if got, want := g.Ignored(p, isDir), want.Ignored(p, isDir); got != want {
+					t.Errorf("%q: Ignored(%q, %v) = %v, %q gives %v", tc.pattern, p, isDir, got, tc.canonical, want)
+				}
+			}
+		}
+
+		if got := g.Patterns(); got[len(got)-1] != tc.pattern {
+			t.Errorf("%q: Patterns() reports %q", tc.pattern, got[len(got)-1])
+		}
+	}
+
+	for _, root := range []string{"./", "//", "."} {
+		g := gitignore.NewOptions(opts, root)
+
+		if n := len(g.Patterns()); root != "." && n != 0 {
+			t.Errorf("%q compiled to %d patterns, want none", root, n)
+		}
+
+		if g.Ignored("x", false) || g.Ignored("x", true) {
+			t.Errorf("%q ignores x", root)
+		}
+	}
+
+	if gitignore.New("./foo").Ignored("foo", false) {
+		t.Error(`without NormalizeSlashes "./foo" matched foo, which Git never does`)
+	}
+}
+
+func TestIgnoreNegations(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"secrets/", "!secrets/", "*.key", "!public.key", `\!literal`}
+
+	dropped := gitignore.NewOptions(gitignore.Options{IgnoreNegations: true}, lines...)
+	positive := gitignore.NewOptions(gitignore.Options{IgnoreNegations: true, NegationsAsPatterns: true}, lines...)
+
+	tests := []struct {
+		path         string
+		isDir        bool
+		wantDropped  bool
+		wantPositive bool
+	}{
+		{path: "secrets", isDir: true, wantDropped: true, wantPositive: true},
+		{path: "secrets/a.txt", wantDropped: true, wantPositive: true},
+		{path: "public.key", wantDropped: true, wantPositive: true},
+		{path: "!literal", wantDropped: true, wantPositive: true},
+		{path: "main.go", wantDropped: false, wantPositive: false},
+	}
+
+	for _, tc := range tests {
+		if got := dropped.Ignored(tc.path, tc.isDir); got != tc.wantDropped {
+			t.Errorf("IgnoreNegations: Ignored(%q) = %v, want %v", tc.path, got, tc.wantDropped)
+		}
+
+		if got := positive.Ignored(tc.path, tc.isDir); got != tc.wantPositive {
+			t.Errorf("NegationsAsPatterns: Ignored(%q) = %v, want %v", tc.path, got, tc.wantPositive)
+		}
+	}
+
+	if got, want := dropped.Patterns(), []string{"secrets/", "*.key", `\!literal`}; !slices.Equal(got, want) {
+		t.Errorf("IgnoreNegations: Patterns() = %q, want %q", got, want)
+	}
+
+	// A negated line compiled as a positive pattern decides on its own.
+	if m := positive.Match("public.key", false); m.Reason != gitignore.ReasonIgnored || m.Pattern != "!public.key" {
+		t.Errorf("NegationsAsPatterns: Match(public.key) = %+v", m)
+	}
+
+	// NegationsAsPatterns alone leaves negations in effect.
+	if gitignore.NewOptions(gitignore.Options{NegationsAsPatterns: true}, lines...).Ignored("public.key", false) {
+		t.Error("NegationsAsPatterns without IgnoreNegations changed how negations match")
+	}
+}
@@ -0,0 +1,318 @@
+package gitignore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pattern is a read-only view of a single compiled pattern of a matcher.
+type Pattern struct {
+	// Index is the position of the pattern among the matcher's patterns, as
+	// returned by Patterns.
+	Index int
+	// the compiled pattern
+	p pattern
+}
+
+// pattern returns a view of the compiled pattern at index i.
+func (g *GitIgnore) pattern(i int) Pattern {
+	return Pattern{Index: i, p: g.patterns[i]}
+}
+
+// Each calls fn for every compiled pattern in order, stopping early when fn
+// returns false. Unlike Patterns it allocates nothing, which suits linters
+// and formatters inspecting large matchers one pattern at a time.
+func (g *GitIgnore) Each(fn func(i int, p Pattern) bool) {
+	for i := range g.patterns {
+		if !fn(i, g.pattern(i)) {
+			return
+		}
+	}
+}
+
+// String returns the pattern line as given.
+func (p Pattern) String() string {
+	return p.p.original
+}
+
+// Negated reports whether the pattern re-includes what it matches ("!pattern").
+func (p Pattern) Negated() bool {
+	return p.p.flags&flagNegative != 0
+}
+
+// DirOnly reports whether the pattern only matches directories (trailing '/').
+func (p Pattern) DirOnly() bool {
+	return p.p.flags&flagDirOnly != 0
+}
+
+// Source names where the pattern came from, or is empty (see Match.Source).
+func (p Pattern) Source() string {
+	return p.p.source
+}
+
+// Line is the 1-based position of the pattern among the lines it was added with.
+func (p Pattern) Line() int {
+	return p.p.line
+}
+
+// IsLiteral reports whether the pattern has no wildcard, escape or class, so
+// it matches by plain string comparison. Directory-only and negated patterns
+// such as "build/" and "!keep.log" are literal; "*.log" and "a\*b" are not.
+func (p Pattern) IsLiteral() bool {
+	return p.p.nowildcardlen == p.p.patternlen
+}
+
+// Scope describes where a pattern can match, as derived from its compiled form.
+type Scope struct {
+	// Anchored reports whether the pattern is matched against the whole path
+	// relative to the .gitignore directory, which is the case when it contains
+	// a '/' anywhere but at the end (a leading one only anchors it).
+	Anchored bool
+	// Basename reports whether the pattern is matched against the final
+	// component of a path at any depth; it is the opposite of Anchored.
+	Basename bool
+	// CrossesDirs reports whether an anchored pattern contains a "**"
+	// component, so it can match across any number of directories.
+	CrossesDirs bool
+	// DirOnly reports whether only directories match (trailing '/').
+	DirOnly bool
+	// Depth is the number of '/' a path matching an anchored pattern contains,
+	// or -1 when it varies or the pattern is a basename pattern.
+	Depth int
+}
+
+// MatchScope returns the anchoring and scope of the pattern.
+func (p Pattern) MatchScope() Scope {
+	s := Scope{
+		Basename: p.p.flags&flagNoDir != 0,
+		DirOnly:  p.DirOnly(),
+		Depth:    p.p.slashes,
+	}
+
+	s.Anchored = !s.Basename
+
+	if s.Basename {
+		s.Depth = -1
+	} else {
+		s.CrossesDirs = hasGlobstarComponent(strings.TrimPrefix(p.p.pattern, "/"))
+	}
+
+	return s
+}
+
+// hasGlobstarComponent reports whether pattern has a '/'-separated component
+// consisting of "**", the only form of '**' that matches across directories.
+func hasGlobstarComponent(pattern string) bool {
+	for component := range strings.SplitSeq(pattern, "/") {
+		if component == "**" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Describe renders the pattern as a plain-English sentence for tooltips and
+// teaching, such as `Ignores directories named "build" anywhere, and their
+// contents`. The sentence is derived from the compiled pattern and quotes it
+// without its '!' and trailing '/'; its wording is not stable and not meant
+// to be parsed.
+func (p Pattern) Describe() string {
+	verb := "Ignores"
+	if p.Negated() {
+		verb = "Re-includes"
+	}
+
+	scope := p.MatchScope()
+	literal := p.IsLiteral()
+	text := strings.TrimPrefix(p.p.pattern, "/")
+
+	var b strings.Builder
+
+	switch {
+	case p.p.flags&flagContents != 0:
+		what := "everything"
+		if scope.DirOnly {
+			what = "every directory"
+		}
+
+		fmt.Fprintf(&b, "%s %s inside the directory %q at the root, but not the directory itself",
+			verb, what, strings.TrimSuffix(text, "/**"))
+	case scope.Basename && literal:
+		fmt.Fprintf(&b, "%s %s named %q anywhere", verb, kinds(scope.DirOnly), text)
+	case scope.Basename:
+		fmt.Fprintf(&b, "%s %s whose name matches %q anywhere", verb, kinds(scope.DirOnly), text)
+	case literal:
+		what := "file or directory"
+		if scope.DirOnly {
+			what = "directory"
+		}
+
+		fmt.Fprintf(&b, "%s the %s %q at the root", verb, what, text)
+	default:
+		fmt.Fprintf(&b, "%s %s whose path from the root matches %q", verb, kinds(scope.DirOnly), text)
+
+		if scope.CrossesDirs {
+			b.WriteString(", where \"**\" spans any number of directories")
+		}
+	}
+
+	if scope.DirOnly && !p.Negated() && p.p.flags&flagContents == 0 {
+		if literal && !scope.Basename {
+			b.WriteString(", and its contents")
+		} else {
+			b.WriteString(", and their contents")
+		}
+	}
+
+	return b.String()
+}
+
+// kinds names what a pattern can match.
+func kinds(dirOnly bool) string {
+	if dirOnly {
+		return "directories"
+	}
+
+	return "files and directories"
+}
+
+// CandidatesFor returns, in order, the patterns whose structure matches
+// pathname if it were a directory, i.e. ignoring the directory-only filter.
+// Comparing the result with DirOnly explains why a rule such as "build/"
+// does not apply to a file named build. Ancestors are not consulted, and
+// paths that Match never scans (empty, absolute, outside the root, or the
+// root itself) have no candidates.
+func (g *GitIgnore) CandidatesFor(pathname string) []Pattern {
+	pathname, _, ok := g.prepare(pathname)
+	if !ok {
+		return nil
+	}
+
+	var out []Pattern
+
+	for i, p := range g.patterns {
+		if g.matchesPattern(p, pathname, true) {
+			out = append(out, g.pattern(i))
+		}
+	}
+
+	return out
+}
+
+// DecisiveRules returns the patterns that must all be removed to change
+// whether pathname is ignored: the pattern deciding Match, then the one that
+// would decide once it is gone, and so on until the result flips. A simple
+// ignore or re-include is decided by one rule, while a path matched by several
+// exclusions yields all of them, and a negation blocked by an excluded
+// ancestor yields the ancestor's exclusion. A path that nothing ignores has no
+// decisive rules, as no removal can change the result.
+func (g *GitIgnore) DecisiveRules(pathname string, isDir bool) []Pattern {
+	ignored := g.Match(pathname, isDir).Ignored
+	removed := make([]bool, len(g.patterns))
+
+	var out []Pattern
+
+	for {
+		sub, indices := g.without(removed)
+
+		d := sub.decide(pathname, isDir)
+		if d.index < 0 || d.match.Ignored != ignored {
+			return out
+		}
+
+		i := indices[d.index]
+		removed[i] = true
+		out = append(out, g.pattern(i))
+	}
+}
+
+// without returns a matcher with g's options and the patterns not marked in
+// removed, along with the index in g of each of its patterns.
+func (g *GitIgnore) without(removed []bool) (*GitIgnore, []int) {
+	sub := &GitIgnore{opts: g.opts}
+	indices := make([]int, 0, len(g.patterns))
+
+	for i, p := range g.patterns {
+		if !removed[i] {
+			sub.add(p)
+
+			indices = append(indices, i)
+		}
+	}
+
+	return sub, indices
+}
+
+// SuggestReinclude returns the negation lines that, appended to g's patterns,
+// would make pathname not ignored, or nil if it is not ignored. Git never
+// re-includes a path whose parent directory is excluded, so when an ancestor
+// is excluded the lines first re-include it, outermost first: under
+// "build/**", build/a/keep.txt needs "!/build/a/" and then
+// "!/build/a/keep.txt", while under a plain "build/" the single line
+// "!/build/" suffices, as nothing then matches build/keep.txt itself.
+// Each line is rooted and escaped to match exactly the one path it names.
+// Paths that Match never scans, such as the root itself, and matchers compiled
+// with Options.IgnoreNegations get no suggestions.
+func (g *GitIgnore) SuggestReinclude(pathname string, isDir bool) []string {
+	input, inputIsDir := g.opts.input(pathname, isDir)
+
+	cleaned, _, ok := g.clean(input)
+	if !ok || g.opts.IgnoreNegations {
+		return nil
+	}
+
+	_, negation := g.opts.prefixes()
+
+	var lines []string
+
+	v := g.clone()
+
+	// Each round re-includes the outermost excluded ancestor, which a
+	// negation of anything below cannot get past, or else the path itself,
+	// so a path with n components needs at most n lines.
+	for range strings.Count(cleaned, "/") + 1 {
+		if !v.Ignored(pathname, isDir) {
+			return lines
+		}
+
+		line := string(negation) + "/"
+
+		_, ancestor := v.parentExcluded(cleaned)
+
+		switch {
+		case ancestor != "" && !g.opts.AllowReincludeUnderExcluded:
+			line += escapeLiteral(ancestor) + "/"
+		case inputIsDir:
+			line += escapeLiteral(cleaned) + "/"
+		default:
+			line += escapeLiteral(cleaned)
+		}
+
+		lines = append(lines, line)
+		v.Append(line)
+	}
+
+	if v.Ignored(pathname, isDir) {
+		return nil
+	}
+
+	return lines
+}
+
+// escapeLiteral escapes s for use in a pattern matching exactly s: glob
+// meta-characters get a backslash, as does a trailing space, which would
+// otherwise be trimmed.
+func escapeLiteral(s string) string {
+	var b strings.Builder
+
+	for i := range len(s) {
+		if isGlobSpecial(s[i]) || (s[i] == ' ' && i == len(s)-1) {
+			b.WriteByte('\\')
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package gitignore_test
+
+import (
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestMatchScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    gitignore.Scope
+	}{
+		{pattern: "*.log", path: "a/b.log", want: gitignore.Scope{Basename: true, Depth: -1}},
+		{pattern: "build/", path: "build", want: gitignore.Scope{Basename: true, DirOnly: true, Depth: -1}},
+		{pattern: "/build", path: "build", want: gitignore.Scope{Anchored: true, Depth: 0}},
+		{pattern: "!/keep", path: "keep", want: gitignore.Scope{Anchored: true, Depth: 0}},
+		{pattern: "src/*.go", path: "src/a.go", want: gitignore.Scope{Anchored: true, Depth: 1}},
+		{
+			pattern: "a/**/b/",
+			path:    "a/x/y/b",
+			want:    gitignore.Scope{Anchored: true, CrossesDirs: true, DirOnly: true, Depth: -1},
+		},
+		{pattern: "!docs/**", path: "docs/x", want: gitignore.Scope{Anchored: true, CrossesDirs: true, Depth: -1}},
+		{pattern: "a**/b", path: "abc/b", want: gitignore.Scope{Anchored: true, Depth: -1}}, // not a "**" component
+		{pattern: "[ab]/c", path: "a/c", want: gitignore.Scope{Anchored: true, Depth: -1}},
+	}
+
+	for _, tc := range tests {
+		candidates := gitignore.New(tc.pattern).CandidatesFor(tc.path)
+		if len(candidates) != 1 {
+			t.Fatalf("%q does not match %q", tc.pattern, tc.path)
+		}
+
+		if got := candidates[0].MatchScope(); got != tc.want {
+			t.Errorf("%q: MatchScope() = %+v, want %+v", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    string
+	}{
+		{
+			pattern: "*.log", path: "a.log",
+			want: `Ignores files and directories whose name matches "*.log" anywhere`,
+		},
+		{
+			pattern: "build/", path: "build",
+			want: `Ignores directories named "build" anywhere, and their contents`,
+		},
+		{
+			pattern: "/build/", path: "build",
+			want: `Ignores the directory "build" at the root, and its contents`,
+		},
+		{
+			pattern: "/Makefile", path: "Makefile",
+			want: `Ignores the file or directory "Makefile" at the root`,
+		},
+		{
+			pattern: "!keep.log", path: "keep.log",
+			want: `Re-includes files and directories named "keep.log" anywhere`,
+		},
+		{
+			pattern: "!/dist/", path: "dist",
+			want: `Re-includes the directory "dist" at the root`,
+		},
+		{
+			pattern: "src/*.go", path: "src/a.go",
+			want: `Ignores files and directories whose path from the root matches "src/*.go"`,
+		},
+		{
+			pattern: "a/**/b/", path: "a/x/b",
+			want: `Ignores directories whose path from the root matches "a/**/b", ` +
+				`where "**" spans any number of directories, and their contents`,
+		},
+		{
+			pattern: "vendor/**", path: "vendor/x",
+			want: `Ignores everything inside the directory "vendor" at the root, but not the directory itself`,
+		},
+	}
+
+	for _, tc := range tests {
+		candidates := gitignore.New(tc.pattern).CandidatesFor(tc.path)
+		if len(candidates) != 1 {
+			t.Fatalf("%q does not match %q", tc.pattern, tc.path)
+		}
+
+		if got := candidates[0].Describe(); got != tc.want {
+			t.Errorf("%q: Describe() =\n%s\nwant\n%s", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	g := gitignore.New("*.log", "# comment", "build/", "!keep.log", "/dist")
+
+	var got []string
+
+	g.Each(func(i int, p gitignore.Pattern) bool {
+		if p.Index != i {
+			t.Errorf("Each: index %d, Pattern.Index %d", i, p.Index)
+		}
+
+		got = append(got, p.String())
+
+		return !p.Negated()
+	})
+
+	if want := []string{"*.log", "build/", "!keep.log"}; !slices.Equal(got, want) {
+		t.Errorf("Each visited %q, want %q", got, want)
+	}
+
+	var dirOnly int
+
+	if n := testing.AllocsPerRun(100, func() {
+		g.Each(func(_ int, p gitignore.Pattern) bool {
+			if p.DirOnly() {
+				dirOnly++
+			}
+
+			return true
+		})
+	}); n != 0 {
+		t.Errorf("Each allocated %v times per run, want 0", n)
+	}
+}
+
+func TestIsLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{pattern: "build", want: true},
+		{pattern: "build/", want: true},
+		{pattern: "/src/main.go", want: true},
+		{pattern: "!keep.log", want: true},
+		{pattern: "*.log", want: false},
+		{pattern: "docs/**", want: false},
+		{pattern: "file?.txt", want: false},
+		{pattern: "[ab]", want: false},
+		{pattern: "a\\*b", want: false},
+		{pattern: "\\#notes", want: true}, // the leading escape is dropped when parsing
+	}
+
+	for _, tc := range tests {
+		var got bool
+
+		gitignore.New(tc.pattern).Each(func(_ int, p gitignore.Pattern) bool {
+			got = p.IsLiteral()
+
+			return false
+		})
+
+		if got != tc.want {
+			t.Errorf("%q: IsLiteral() = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestDecisiveRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     []string
+	}{
+		{name: "single exclusion", patterns: []string{"*.log", "build/"}, path: "a.log", want: []string{"*.log"}},
+		{name: "negation", patterns: []string{"*.log", "!keep.log"}, path: "keep.log", want: []string{"!keep.log"}},
+		{
+			name:     "redundant exclusions",
+			patterns: []string{"*.log", "debug.log", "!other.log"},
+			path:     "debug.log",
+			want:     []string{"debug.log", "*.log"},
+		},
+		{
+			name:     "negation blocked by excluded ancestor",
+			patterns: []string{"build/", "!build/keep.txt"},
+			path:     "build/keep.txt",
+			want:     []string{"build/"},
+		},
+		{
+			name:     "ancestor then direct exclusion",
+			patterns: []string{"*.txt", "build/", "!build/keep.txt"},
+			path:     "build/keep.txt",
+			want:     []string{"build/"},
+		},
+		{
+			name:     "direct exclusion then ancestor",
+			patterns: []string{"build/", "*.txt"},
+			path:     "build/keep.txt",
+			want:     []string{"*.txt", "build/"},
+		},
+		{name: "directory only", patterns: []string{"build/"}, path: "build", isDir: true, want: []string{"build/"}},
+		{name: "file not matched by directory rule", patterns: []string{"build/"}, path: "build"},
+		{name: "no match", patterns: []string{"*.log"}, path: "main.go"},
+	}
+
+	for _, tc := range tests {
+		g := gitignore.New(tc.patterns...)
+
+		var got []string
+
+		for _, p := range g.DecisiveRules(tc.path, tc.isDir) {
+			got = append(got, p.String())
+		}
+
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: DecisiveRules(%q) = %q, want %q", tc.name, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestSuggestReinclude(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     []string
+	}{
+		{name: "direct match", patterns: []string{"*.log"}, path: "logs/keep.log", want: []string{"!/logs/keep.log"}},
+		{name: "directory", patterns: []string{"build/"}, path: "build", isDir: true, want: []string{"!/build/"}},
+		{name: "excluded parent", patterns: []string{"build/"}, path: "build/keep.txt", want: []string{"!/build/"}},
+		{
+			name:     "excluded parent and direct match",
+			patterns: []string{"build/", "*.txt"},
+			path:     "build/keep.txt",
+			want:     []string{"!/build/", "!/build/keep.txt"},
+		},
+		{
+			name:     "nested excluded ancestors",
+			patterns: []string{"build/**"},
+			path:     "build/a/b/keep.txt",
+			want:     []string{"!/build/a/", "!/build/a/b/", "!/build/a/b/keep.txt"},
+		},
+		{
+			name:     "blocked negation",
+			patterns: []string{"vendor/", "!vendor/lib/keep.txt"},
+			path:     "vendor/lib/keep.txt",
+			want:     []string{"!/vendor/"},
+		},
+		{name: "glob characters", patterns: []string{"*"}, path: "a[1]*.txt", want: []string{`!/a\[1]\*.txt`}},
+		{name: "trailing space", patterns: []string{"*"}, path: "x ", want: []string{`!/x\ `}},
+		{name: "cleaned path", patterns: []string{"*.log"}, path: "./a/../b.log", want: []string{"!/b.log"}},
+		{name: "not ignored", patterns: []string{"*.log"}, path: "main.go"},
+	}
+
+	lenient := gitignore.Options{AllowReincludeUnderExcluded: true}
+
+	for _, tc := range tests {
+		g := gitignore.New(tc.patterns...)
+
+		got := g.SuggestReinclude(tc.path, tc.isDir)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: SuggestReinclude(%q) = %q, want %q", tc.name, tc.path, got, tc.want)
+		}
+
+		if g.Append(got...); g.Ignored(tc.path, tc.isDir) {
+			t.Errorf("%s: %q is still ignored after appending %q", tc.name, tc.path, got)
+		}
+	}
+
+	// Without Git's limitation the path itself can be re-included directly.
+	got := gitignore.NewOptions(lenient, "vendor/", "*.txt").SuggestReinclude("vendor/lib/keep.txt", false)
+	if want := []string{"!/vendor/lib/keep.txt"}; !slices.Equal(got, want) {
+		t.Errorf("AllowReincludeUnderExcluded: SuggestReinclude = %q, want %q", got, want)
+	}
+
+	if got := gitignore.NewOptions(gitignore.Options{IgnoreNegations: true}, "*.log").SuggestReinclude("a.log", false); got != nil {
+		t.Errorf("IgnoreNegations: SuggestReinclude = %q, want none", got)
+	}
+}
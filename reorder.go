@@ -0,0 +1,106 @@
+package gitignore
+
+import (
+	"slices"
+	"strings"
+)
+
+// ReorderableGroups partitions the pattern indices into consecutive groups
+// whose patterns can be sorted freely within each group, keeping the groups
+// in order, without changing whether any path is ignored. This is what a
+// formatter needs to sort a .gitignore safely. A group never holds a pattern
+// and a negation that might match the same path; the analysis is
+// conservative, so patterns it cannot prove disjoint end up in separate
+// groups. Which pattern Match reports as deciding may change.
+func (g *GitIgnore) ReorderableGroups() [][]int {
+	var groups [][]int
+
+	var current []int
+
+	for i := range g.patterns {
+		if slices.ContainsFunc(current, func(j int) bool { return g.orderDependent(j, i) }) {
+			groups = append(groups, current)
+			current = nil
+		}
+
+		current = append(current, i)
+	}
+
+	if current != nil {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+// orderDependent reports whether swapping the patterns at i and j might change
+// an outcome: they have opposite signs and are not provably disjoint.
+func (g *GitIgnore) orderDependent(i, j int) bool {
+	a, b := &g.patterns[i], &g.patterns[j]
+	if a.flags&flagNegative == b.flags&flagNegative {
+		return false
+	}
+
+	return !g.disjoint(a, b) && !g.disjoint(b, a)
+}
+
+// disjoint reports whether no path can match both a and b, judged from their
+// literal prefixes, suffixes and depths, and exactly when a is a literal that
+// b can be matched against. Per-component folding is treated as folding
+// everything, which only makes more patterns overlap.
+func (g *GitIgnore) disjoint(a, b *pattern) bool {
+	prefixA, suffixA := g.literalEnds(a)
+	prefixB, suffixB := g.literalEnds(b)
+
+	if !compatible(suffixA, suffixB, strings.HasSuffix) {
+		return true
+	}
+
+	if basenameA, basenameB := a.flags&flagNoDir != 0, b.flags&flagNoDir != 0; basenameA == basenameB {
+		if !compatible(prefixA, prefixB, strings.HasPrefix) {
+			return true
+		}
+
+		if !basenameA && a.slashes >= 0 && b.slashes >= 0 && a.slashes != b.slashes {
+			return true
+		}
+
+		// A literal matches one path (or basename), against which b is tested
+		// directly. Per-component folding depends on the path, so it is skipped.
+		if a.nowildcardlen == a.patternlen && g.opts.CaseFoldFunc == nil {
+			return !g.matchesPattern(*b, strings.TrimPrefix(a.pattern, "/"), true)
+		}
+	}
+
+	return false
+}
+
+// literalEnds returns the literal text before the first and after the last
+// special byte of the pattern, folded when matching folds, and without the
+// slashes that a '**' next to them could make optional. Closing brackets
+// count as special, so neither end reaches into a class.
+func (g *GitIgnore) literalEnds(p *pattern) (string, string) {
+	text := strings.TrimPrefix(p.pattern, "/")
+	if g.folding(p.flags) {
+		text = FoldPath(text)
+	}
+
+	prefix, suffix := text, text
+	if i := strings.IndexAny(text, "*?[]\\"); i >= 0 {
+		prefix = text[:i]
+		suffix = text[strings.LastIndexAny(text, "*?[]\\")+1:]
+	}
+
+	return strings.TrimSuffix(prefix, "/"), strings.TrimPrefix(suffix, "/")
+}
+
+// compatible reports whether a string can start (or end) with both x and y,
+// that is whether has reports the longer one starting (or ending) with the
+// shorter one.
+func compatible(x, y string, has func(s, part string) bool) bool {
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+
+	return has(x, y)
+}
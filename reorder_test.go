@@ -0,0 +1,108 @@
+package gitignore_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestReorderableGroups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		opt   gitignore.Options
+		lines []string
+		want  [][]int
+	}{
+		{
+			name:  "same sign",
+			lines: []string{"*.log", "build/", "/dist"},
+			want:  [][]int{{0, 1, 2}},
+		},
+		{
+			name:  "overlapping negation",
+			lines: []string{"*.log", "build/", "!keep.log", "node_modules/", "*.tmp"},
+			want:  [][]int{{0, 1}, {2, 3, 4}},
+		},
+		{
+			name:  "disjoint suffixes",
+			lines: []string{"*.log", "!*.go", "*.tmp"},
+			want:  [][]int{{0, 1, 2}},
+		},
+		{
+			name:  "disjoint prefixes and depths",
+			lines: []string{"/src/*.go", "!/lib/*.go", "a/*/b", "!c/d"},
+			want:  [][]int{{0, 1, 2, 3}},
+		},
+		{
+			name:  "globstar may overlap",
+			lines: []string{"docs/**", "!docs/readme.md", "!*.md"},
+			want:  [][]int{{0}, {1, 2}},
+		},
+		{
+			name:  "case folding overlaps",
+			opt:   gitignore.Options{CaseFold: true},
+			lines: []string{"*.LOG", "!keep.log"},
+			want:  [][]int{{0}, {1}},
+		},
+		{
+			name:  "exact case differs",
+			lines: []string{"*.LOG", "!keep.log"},
+			want:  [][]int{{0, 1}},
+		},
+		{
+			name:  "classes",
+			lines: []string{"*.py[cod]", "!x.pyc", "!x.txt"},
+			want:  [][]int{{0}, {1, 2}},
+		},
+	}
+
+	for _, tc := range tests {
+		if got := gitignore.NewOptions(tc.opt, tc.lines...).ReorderableGroups(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ReorderableGroups() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if got := gitignore.New().ReorderableGroups(); got != nil {
+		t.Errorf("empty matcher: ReorderableGroups() = %v, want nil", got)
+	}
+}
+
+func TestReorderableGroupsPreserveOutcomes(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"*.log", "build/", "!keep.log", "node_modules/", "*.tmp", "!/src/*.tmp",
+		"docs/**", "!docs/readme.md", "*.md", "!CHANGELOG.md", "a/**/b", "!a/x/b", "/x/*", "!x/y",
+	}
+
+	paths := []string{
+		"app.log", "keep.log", "src/keep.log", "build", "build/keep.log", "node_modules/a.js",
+		"a.tmp", "src/a.tmp", "src/x/a.tmp", "docs/readme.md", "docs/guide.md", "README.md",
+		"CHANGELOG.md", "sub/CHANGELOG.md", "a/b", "a/x/b", "a/x/y/b", "x/y", "x/z", "x/y/z",
+	}
+
+	g := gitignore.New(lines...)
+
+	// Reversing every group is as far from the original order as sorting can go.
+	var reordered []string
+
+	for _, group := range g.ReorderableGroups() {
+		for _, i := range slices.Backward(group) {
+			reordered = append(reordered, lines[i])
+		}
+	}
+
+	r := gitignore.New(reordered...)
+
+	for _, path := range paths {
+		for _, isDir := range []bool{false, true} {
+			if got, want := r.Ignored(path, isDir), g.Ignored(path, isDir); got != want {
+				t.Errorf("Ignored(%q, %v) = %v after reordering to %q, want %v", path, isDir, got, reordered, want)
+			}
+		}
+	}
+}
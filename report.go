@@ -0,0 +1,166 @@
+package gitignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Report explains how each path is matched, one tab-separated line per path
+// in input order after a header line:
+//
+//	PATH	IGNORED	PATTERN	REASON
+//
+// IGNORED is "true" or "false", PATTERN is the deciding pattern or "-" when
+// none applies, and REASON is the Reason, followed by the excluded ancestor
+// in parentheses when a parent directory decided. isDir reports whether a
+// path is a directory; when nil, a trailing '/' marks directories as in
+// IgnoredPath. The output is deterministic and meant for piping to tools
+// such as column or awk.
+func (g *GitIgnore) Report(paths []string, isDir func(string) bool) string {
+	var b strings.Builder
+
+	b.WriteString("PATH\tIGNORED\tPATTERN\tREASON\n")
+
+	for _, p := range paths {
+		m, ancestor := g.MatchFull(g.pathIsDir(p, isDir))
+
+		pattern := m.Pattern
+		if pattern == "" {
+			pattern = "-"
+		}
+
+		reason := m.Reason.String()
+		if ancestor != "" {
+			reason += " (" + ancestor + ")"
+		}
+
+		b.WriteString(p)
+		b.WriteByte('\t')
+		b.WriteString(strconv.FormatBool(m.Ignored))
+		b.WriteByte('\t')
+		b.WriteString(pattern)
+		b.WriteByte('\t')
+		b.WriteString(reason)
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// FilterWithReasons splits paths into the ones kept, in input order, and the
+// ignored ones, mapped to the Match that ignored them. isDir is interpreted as
+// in Report. Duplicate paths appear in kept once per occurrence.
+func (g *GitIgnore) FilterWithReasons(paths []string, isDir func(string) bool) ([]string, map[string]Match) {
+	kept := make([]string, 0, len(paths))
+	ignored := make(map[string]Match)
+
+	for _, p := range paths {
+		if m := g.Match(g.pathIsDir(p, isDir)); m.Ignored {
+			ignored[p] = m
+		} else {
+			kept = append(kept, p)
+		}
+	}
+
+	return kept, ignored
+}
+
+// MatchBatch matches each pathspec like Match and returns the results in
+// input order, mirroring the multi-argument form of git check-ignore. isDir
+// is interpreted as in Report. Ancestor directories shared by several
+// pathspecs are evaluated once per batch.
+func (g *GitIgnore) MatchBatch(pathspecs []string, isDir func(string) bool) []Match {
+	out := make([]Match, len(pathspecs))
+	seen := make(map[string]int)
+
+	for i, p := range pathspecs {
+		pathname, dir := g.pathIsDir(p, isDir)
+		out[i] = g.decideIn(pathname, dir, seen).match
+	}
+
+	return out
+}
+
+// PartitionZ reads NUL-separated paths from r and writes the ignored ones to
+// w, each followed by a NUL, in input order, like git check-ignore -z --stdin.
+// A trailing '/' marks a directory, as in Report with a nil isDir, and is
+// kept in the output. Input is processed as it is read and output flushed
+// whenever more input must be awaited, so r may be a pipe from a co-process;
+// the final path need not be NUL-terminated.
+func (g *GitIgnore) PartitionZ(r io.Reader, w io.Writer) error {
+	return scanRecords(r, w, 0, func(p string) string {
+		if p == "" || !g.Match(g.pathIsDir(p, nil)).Ignored {
+			return ""
+		}
+
+		return p + "\x00"
+	})
+}
+
+// ScanPaths reads one path per line from r, such as a file list or standard
+// input, and writes format's result for each path and its Match to w on its
+// own line, in input order. A trailing '/' marks a directory as in PartitionZ,
+// and the path is passed to format as read. Empty lines and empty results are
+// skipped, so format can also filter. Line ends may be CRLF.
+func (g *GitIgnore) ScanPaths(r io.Reader, w io.Writer, format func(Match, string) string) error {
+	return scanRecords(r, w, '\n', func(p string) string {
+		if p = strings.TrimSuffix(p, "\r"); p == "" {
+			return ""
+		}
+
+		if out := format(g.Match(g.pathIsDir(p, nil)), p); out != "" {
+			return out + "\n"
+		}
+
+		return ""
+	})
+}
+
+// scanRecords calls fn with every delim-terminated record read from r,
+// without the delimiter, and writes what fn returns to w. Output is flushed
+// before a read may block, so a co-process gets every answer before sending
+// more input.
+func scanRecords(r io.Reader, w io.Writer, delim byte, fn func(record string) string) error {
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+
+	for {
+		record, err := in.ReadString(delim)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading paths: %w", err)
+		}
+
+		if _, werr := out.WriteString(fn(strings.TrimSuffix(record, string(delim)))); werr != nil {
+			return fmt.Errorf("writing results: %w", werr)
+		}
+
+		if err != nil || in.Buffered() == 0 {
+			if werr := out.Flush(); werr != nil {
+				return fmt.Errorf("writing results: %w", werr)
+			}
+		}
+
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// pathIsDir returns the path to match for p and whether it is a directory,
+// using isDir when set and a trailing '/' otherwise. The path keeps the kind
+// under Options.InferDirFromSlash (see keepDir).
+func (g *GitIgnore) pathIsDir(p string, isDir func(string) bool) (string, bool) {
+	dir := false
+
+	if isDir != nil {
+		dir = isDir(p)
+	} else {
+		p, dir = splitDirSuffix(p)
+	}
+
+	return g.opts.keepDir(p, dir), dir
+}
@@ -0,0 +1,176 @@
+package gitignore_test
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/")
+
+	want := "PATH\tIGNORED\tPATTERN\tREASON\n" +
+		"app.log\ttrue\t*.log\tignored\n" +
+		"keep.log\tfalse\t!keep.log\tnegated\n" +
+		"build/\ttrue\tbuild/\tignored\n" +
+		"build/out.bin\ttrue\tbuild/\tparent excluded (build)\n" +
+		"src/main.go\tfalse\t-\tno match\n"
+
+	paths := []string{"app.log", "keep.log", "build/", "build/out.bin", "src/main.go"}
+	if got := g.Report(paths, nil); got != want {
+		t.Errorf("Report() =\n%s\nwant\n%s", got, want)
+	}
+
+	isDir := func(p string) bool { return p == "build" }
+
+	want = "PATH\tIGNORED\tPATTERN\tREASON\n" +
+		"build\ttrue\tbuild/\tignored\n" +
+		"build/x\ttrue\tbuild/\tparent excluded (build)\n"
+
+	if got := g.Report([]string{"build", "build/x"}, isDir); got != want {
+		t.Errorf("Report(isDir) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFilterWithReasons(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/")
+
+	paths := []string{"app.log", "keep.log", "build/", "build/out.bin", "src/main.go", "build"}
+
+	kept, ignored := g.FilterWithReasons(paths, nil)
+
+	if want := []string{"keep.log", "src/main.go", "build"}; !slices.Equal(kept, want) {
+		t.Errorf("kept = %q, want %q", kept, want)
+	}
+
+	want := map[string]gitignore.Match{
+		"app.log":       {Ignored: true, Pattern: "*.log", Reason: gitignore.ReasonIgnored, Line: 1},
+		"build/":        {Ignored: true, Pattern: "build/", Reason: gitignore.ReasonIgnored, Line: 3},
+		"build/out.bin": {Ignored: true, Pattern: "build/", Reason: gitignore.ReasonParentExcluded, Line: 3},
+	}
+
+	if !maps.Equal(ignored, want) {
+		t.Errorf("ignored = %+v, want %+v", ignored, want)
+	}
+
+	kept, ignored = g.FilterWithReasons([]string{"build"}, func(string) bool { return true })
+	if len(kept) != 0 || ignored["build"].Pattern != "build/" {
+		t.Errorf("FilterWithReasons(isDir) = %q, %+v, want build ignored by build/", kept, ignored)
+	}
+}
+
+func TestMatchBatch(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("node_modules/", "!node_modules/keep/", "*.log", "!keep.log", "/dist")
+
+	paths := []string{
+		"node_modules/a/index.js", "node_modules/b/index.js", "node_modules/keep/x.js",
+		"app.log", "src/keep.log", "dist/", "dist/app.js", "src/", "app.log", "../outside.log",
+	}
+
+	got := g.MatchBatch(paths, nil)
+	if len(got) != len(paths) {
+		t.Fatalf("MatchBatch returned %d results for %d paths", len(got), len(paths))
+	}
+
+	for i, p := range paths {
+		if want := g.Match(strings.TrimSuffix(p, "/"), strings.HasSuffix(p, "/")); got[i] != want {
+			t.Errorf("MatchBatch()[%d] for %q = %+v, Match reports %+v", i, p, got[i], want)
+		}
+	}
+}
+
+func TestPartitionZ(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "terminated", in: "app.log\x00keep.log\x00build/\x00build\x00src/main.go\x00", want: "app.log\x00build/\x00"},
+		{name: "unterminated", in: "src/main.go\x00a b.log", want: "a b.log\x00"},
+		{name: "newlines in names", in: "x\ny.log\x00build/a\x00", want: "x\ny.log\x00build/a\x00"},
+		{name: "empty records", in: "\x00\x00", want: ""},
+		{name: "empty", in: "", want: ""},
+	}
+
+	for _, tc := range tests {
+		var out strings.Builder
+
+		if err := g.PartitionZ(strings.NewReader(tc.in), &out); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		if out.String() != tc.want {
+			t.Errorf("%s: PartitionZ() wrote %q, want %q", tc.name, out.String(), tc.want)
+		}
+	}
+
+	errRead := errors.New("read failed")
+	if err := g.PartitionZ(iotest.ErrReader(errRead), io.Discard); !errors.Is(err, errRead) {
+		t.Errorf("read error: got %v, want it wrapped", err)
+	}
+}
+
+func TestScanPaths(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "!keep.log", "build/")
+	in := "app.log\r\nkeep.log\n\nbuild/\nbuild/out.bin\nsrc/main.go"
+
+	verbose := func(m gitignore.Match, p string) string {
+		if m.Pattern == "" {
+			return "::\t" + p
+		}
+
+		return ":" + strconv.Itoa(m.Line) + ":" + m.Pattern + "\t" + p
+	}
+
+	ignoredOnly := func(m gitignore.Match, p string) string {
+		if !m.Ignored {
+			return ""
+		}
+
+		return p
+	}
+
+	tests := []struct {
+		name   string
+		format func(gitignore.Match, string) string
+		want   string
+	}{
+		{
+			name:   "verbose",
+			format: verbose,
+			want:   ":1:*.log\tapp.log\n:2:!keep.log\tkeep.log\n:3:build/\tbuild/\n:3:build/\tbuild/out.bin\n::\tsrc/main.go\n",
+		},
+		{name: "filter", format: ignoredOnly, want: "app.log\nbuild/\nbuild/out.bin\n"},
+	}
+
+	for _, tc := range tests {
+		var out strings.Builder
+
+		if err := g.ScanPaths(strings.NewReader(in), &out, tc.format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		if out.String() != tc.want {
+			t.Errorf("%s: ScanPaths() wrote %q, want %q", tc.name, out.String(), tc.want)
+		}
+	}
+}
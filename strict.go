@@ -0,0 +1,165 @@
+package gitignore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	wildmatch "github.com/idelchi/go-gitignore/wildmatch"
+)
+
+// Warning describes a pattern line that falls outside portable Git semantics.
+type Warning struct {
+	// Line is the 1-based line number, or 0 for problems with the Options themselves.
+	Line int
+	// Text is the offending line as given.
+	Text string
+	// Reason explains the problem.
+	Reason string
+	// Kind classifies findings reported by Lint; it is empty for Validate.
+	Kind LintKind
+	// Related is the line of another pattern involved in a Lint finding, or 0.
+	Related int
+}
+
+// String formats the warning as "line N: reason: text".
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return w.Reason
+	}
+
+	return fmt.Sprintf("line %d: %s: %q", w.Line, w.Reason, w.Text)
+}
+
+// ParseError is returned by Compile and the loading constructors in strict
+// mode for each problem Validate reports. Line is 0 for problems with the
+// Options themselves.
+type ParseError struct {
+	// Line is the 1-based line number, or 0 for problems with the Options themselves.
+	Line int
+	// Text is the offending line as given.
+	Text string
+	// Reason explains the problem.
+	Reason string
+}
+
+// Error formats the error like the corresponding Warning.
+func (e *ParseError) Error() string {
+	return Warning{Line: e.Line, Text: e.Text, Reason: e.Reason}.String()
+}
+
+// Validate reports option settings and lines that are not portable Git
+// .gitignore syntax: extension options with no Git equivalent or that make
+// patterns Git matches fail to match, and patterns that Git accepts but
+// silently never matches (a trailing unescaped backslash, an unterminated
+// character class, an unknown POSIX class, consecutive slashes, or a "." or
+// ".." component), as well as lines dropped for exceeding
+// Options.MaxPatternLength.
+func Validate(opt Options, lines ...string) []Warning {
+	var warnings []Warning
+
+	if opt.CaseFoldFunc != nil {
+		warnings = append(warnings, Warning{Reason: "Options.CaseFoldFunc has no Git equivalent"})
+	}
+
+	if opt.AllowReincludeUnderExcluded {
+		warnings = append(warnings, Warning{Reason: "Options.AllowReincludeUnderExcluded has no Git equivalent"})
+	}
+
+	if opt.IgnoreNegations {
+		warnings = append(warnings, Warning{Reason: "Options.IgnoreNegations has no Git equivalent"})
+	}
+
+	if opt.NormalizeSlashes {
+		warnings = append(warnings, Warning{Reason: "Options.NormalizeSlashes has no Git equivalent"})
+	}
+
+	if opt.MaxStarstar > 0 {
+		warnings = append(warnings, Warning{Reason: "Options.MaxStarstar makes patterns Git matches fail to match"})
+	}
+
+	if comment, negation := opt.prefixes(); comment != '#' || negation != '!' {
+		warnings = append(warnings, Warning{Reason: "custom comment or negation prefixes have no Git equivalent"})
+	}
+
+	for i, line := range lines {
+		if opt.tooLong(line) {
+			warnings = append(warnings, Warning{
+				Line:   i + 1,
+				Text:   line,
+				Reason: "pattern exceeds Options.MaxPatternLength and is dropped",
+			})
+
+			continue
+		}
+
+		p, ok := parsePattern(line, opt)
+		if !ok {
+			continue
+		}
+
+		if err := wildmatch.Check(p.pattern); err != nil {
+			warnings = append(warnings, Warning{Line: i + 1, Text: line, Reason: err.Error()})
+		}
+
+		if emptyComponent(&p) {
+			warnings = append(warnings, Warning{
+				Line:   i + 1,
+				Text:   line,
+				Reason: "consecutive slashes form an empty path component, which no path has",
+			})
+		}
+
+		if dotComponent(&p) {
+			warnings = append(warnings, Warning{
+				Line:   i + 1,
+				Text:   line,
+				Reason: `a "." or ".." component never matches, as paths are cleaned before matching`,
+			})
+		}
+	}
+
+	return warnings
+}
+
+// Compile is like NewOptions, but when opt.Strict is set it refuses input for
+// which Validate reports any warning, returning one *ParseError per warning
+// joined with errors.Join. Strict mode changes diagnostics only; the compiled
+// matcher behaves exactly as one built by NewOptions.
+func Compile(opt Options, lines ...string) (*GitIgnore, error) {
+	if opt.Strict {
+		warnings := Validate(opt, lines...)
+		if len(warnings) > 0 {
+			errs := make([]error, len(warnings))
+
+			for i, w := range warnings {
+				errs[i] = &ParseError{Line: w.Line, Text: w.Text, Reason: w.Reason}
+			}
+
+			return nil, errors.Join(errs...)
+		}
+	}
+
+	return NewOptions(opt, lines...), nil
+}
+
+// emptyComponent reports whether p contains "//". Git strips a single
+// trailing '/' and matches the rest against paths without collapsing
+// slashes, so "a//b", "//a" and "foo//" never match anything.
+func emptyComponent(p *pattern) bool {
+	return strings.Contains(p.pattern, "//") || strings.HasSuffix(p.pattern, "/")
+}
+
+// dotComponent reports whether p has a "." or ".." component. Git does not
+// normalize patterns, and cleaned paths have no such component, so ".",
+// "./foo" and "a/../b" never match anything; Options.NormalizeSlashes
+// rewrites only the leading "./" form.
+func dotComponent(p *pattern) bool {
+	for component := range strings.SplitSeq(strings.TrimPrefix(p.pattern, "/"), "/") {
+		if component == "." || component == ".." {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package gitignore_test
+
+import (
+	"testing"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"*.log",
+		"foo\\",
+		"[abc",
+		"[[:word:]]",
+		"[[:alpha:]]*",
+		"\\[literal",
+		"[]]",
+		"# [unterminated comment",
+		"trailing\\ ",
+		"a//b",
+		"foo//",
+		"a/b/",
+		".",
+		"../foo",
+		"a/./b",
+		"..foo",
+	}
+
+	warnings := gitignore.Validate(gitignore.Options{}, lines...)
+
+	wantLines := []int{2, 3, 4, 10, 11, 13, 14, 15}
+	if len(warnings) != len(wantLines) {
+		t.Fatalf("got %d warnings %v, want lines %v", len(warnings), warnings, wantLines)
+	}
+
+	for i, w := range warnings {
+		if w.Line != wantLines[i] {
+			t.Errorf("warning %d: got line %d, want %d (%s)", i, w.Line, wantLines[i], w)
+		}
+	}
+}
+
+func TestCompileStrict(t *testing.T) {
+	t.Parallel()
+
+	if _, err := gitignore.Compile(gitignore.Options{}, "foo\\"); err != nil {
+		t.Errorf("non-strict Compile: unexpected error: %v", err)
+	}
+
+	if _, err := gitignore.Compile(gitignore.Options{Strict: true}, "*.log", "foo\\"); err == nil {
+		t.Error("strict Compile: expected error for trailing backslash")
+	}
+
+	opts := gitignore.Options{Strict: true, CaseFoldFunc: func(string) bool { return true }}
+	if _, err := gitignore.Compile(opts, "*.log"); err == nil {
+		t.Error("strict Compile: expected error for CaseFoldFunc")
+	}
+
+	opts = gitignore.Options{Strict: true, AllowReincludeUnderExcluded: true}
+	if _, err := gitignore.Compile(opts, "*.log"); err == nil {
+		t.Error("strict Compile: expected error for AllowReincludeUnderExcluded")
+	}
+
+	opts = gitignore.Options{Strict: true, NormalizeSlashes: true}
+	if _, err := gitignore.Compile(opts, "*.log"); err == nil {
+		t.Error("strict Compile: expected error for NormalizeSlashes")
+	}
+
+	opts = gitignore.Options{Strict: true, MaxStarstar: 2}
+	if _, err := gitignore.Compile(opts, "**/a/**/b/**/c"); err == nil {
+		t.Error("strict Compile: expected error for MaxStarstar")
+	}
+
+	if w := gitignore.Validate(gitignore.Options{MaxStarstar: 1}); len(w) != 1 || w[0].Line != 0 {
+		t.Errorf("Validate with MaxStarstar: got %v, want one option warning", w)
+	}
+
+	g, err := gitignore.Compile(gitignore.Options{Strict: true}, "*.log", "!keep.log")
+	if err != nil {
+		t.Fatalf("strict Compile: unexpected error: %v", err)
+	}
+
+	if !g.Ignored("a.log", false) || g.Ignored("keep.log", false) {
+		t.Error("strict Compile: matcher does not behave like NewOptions")
+	}
+}
@@ -0,0 +1,304 @@
+package gitignore
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// PatternReachesAnything walks fsys below root and reports whether the pattern
+// at index is the deciding rule for at least one existing path.
+// Paths are evaluated relative to root, and '.git' directories are skipped.
+// The walk stops at the first path decided by the pattern.
+func (g *GitIgnore) PatternReachesAnything(index int, fsys fs.FS, root string) (bool, error) {
+	if index < 0 || index >= len(g.patterns) {
+		return false, fmt.Errorf("pattern index %d out of range [0, %d)", index, len(g.patterns))
+	}
+
+	reached := false
+
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := relativeTo(root, name)
+		if rel == "" {
+			return nil
+		}
+
+		if d.IsDir() && path.Base(rel) == ".git" {
+			return fs.SkipDir
+		}
+
+		if g.decide(g.opts.keepDir(rel, d.IsDir()), d.IsDir()).index == index {
+			reached = true
+
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return reached, nil
+}
+
+// DirMayContainIncluded reports whether any path below the directory dir could
+// end up not ignored. Git never re-includes a path whose parent directory is
+// excluded, so this is false exactly when dir itself is ignored; otherwise it
+// conservatively reports true. With Options.AllowReincludeUnderExcluded, an
+// ignored dir may still contain included paths if a negation could match
+// below it.
+func (g *GitIgnore) DirMayContainIncluded(dir string) bool {
+	return !g.Ignored(g.opts.keepDir(dir, true), true) || g.mayRescueBelow(dir)
+}
+
+// ShouldSkipDir reports whether a walker may prune the directory relPath
+// (e.g. by returning fs.SkipDir): it is ignored and no negation can rescue
+// anything below it.
+func (g *GitIgnore) ShouldSkipDir(relPath string) bool {
+	return g.Ignored(g.opts.keepDir(relPath, true), true) && !g.mayRescueBelow(relPath)
+}
+
+// WalkOptions configures Walk.
+type WalkOptions struct {
+	// OnPrune, when non-nil, is called for every directory Walk skips because
+	// it is ignored, with the path as passed to fn and the deciding Match.
+	OnPrune func(path string, by Match)
+}
+
+// Walk walks fsys below root like fs.WalkDir, but calls fn only for paths g
+// does not ignore. Ignored directories are pruned without being read, as
+// decided by ShouldSkipDir, and '.git' directories are skipped. Paths are
+// matched relative to root; fn receives them as fs.WalkDir names them.
+func (g *GitIgnore) Walk(fsys fs.FS, root string, opts WalkOptions, fn fs.WalkDirFunc) error {
+	return g.walk(fsys, root, opts, fn, nil)
+}
+
+// ClassifyFS walks fsys below root as Walk does and returns, each sorted, the
+// paths it leaves out and the paths it visits, such as for build manifests or
+// snapshot tests of a tree. kept holds exactly the paths Walk passes to its
+// callback, other than root itself. ignored holds the ignored files and
+// directories; a pruned directory appears without its contents, as Walk never
+// reads it. '.git' directories appear in neither list. Paths are named as
+// fs.WalkDir names them, and the first error from fsys is returned.
+func (g *GitIgnore) ClassifyFS(fsys fs.FS, root string) (ignored, kept []string, err error) {
+	err = g.walk(fsys, root, WalkOptions{}, func(name string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if name != root {
+			kept = append(kept, name)
+		}
+
+		return nil
+	}, func(name string) {
+		ignored = append(ignored, name)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slices.Sort(ignored)
+	slices.Sort(kept)
+
+	return ignored, kept, nil
+}
+
+// walk implements Walk, additionally calling skip, when non-nil, for every
+// ignored path it does not pass to fn, whether pruned or not.
+func (g *GitIgnore) walk(fsys fs.FS, root string, opts WalkOptions, fn fs.WalkDirFunc, skip func(name string)) error {
+	if skip == nil {
+		skip = func(string) {}
+	}
+
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(name, d, err)
+		}
+
+		rel := relativeTo(root, name)
+		if rel == "" {
+			return fn(name, d, nil)
+		}
+
+		if !d.IsDir() {
+			if g.Ignored(rel, false) {
+				skip(name)
+
+				return nil
+			}
+
+			return fn(name, d, nil)
+		}
+
+		if path.Base(rel) == ".git" {
+			return fs.SkipDir
+		}
+
+		m := g.Match(g.opts.keepDir(rel, true), true)
+		if !m.Ignored {
+			return fn(name, d, nil)
+		}
+
+		skip(name)
+
+		if g.mayRescueBelow(rel) {
+			// Ignored itself, but something below may be re-included.
+			return nil
+		}
+
+		if opts.OnPrune != nil {
+			opts.OnPrune(name, m)
+		}
+
+		return fs.SkipDir
+	})
+}
+
+// mayRescueBelow reports whether, under Options.AllowReincludeUnderExcluded,
+// a negation could re-include something below the ignored directory dir.
+// dir is normalized as for matching, so that it names the directory Ignored
+// decided; the root and paths Ignored never matches conservatively report true.
+func (g *GitIgnore) mayRescueBelow(dir string) bool {
+	if !g.opts.AllowReincludeUnderExcluded {
+		return false
+	}
+
+	dir, _, ok := g.prepare(dir)
+
+	return !ok || g.negationMayReach(dir)
+}
+
+// negationMayReach reports whether a negated pattern could match some path
+// below dir. Patterns whose leading directories are not literal are assumed
+// to reach anywhere.
+func (g *GitIgnore) negationMayReach(dir string) bool {
+	for i := range g.patterns {
+		p := &g.patterns[i]
+
+		if p.flags&flagNegative == 0 {
+			continue
+		}
+
+		if p.flags&flagNoDir != 0 {
+			return true
+		}
+
+		lit := literalDir(p.pattern)
+		if lit == "" || lit == dir || strings.HasPrefix(lit, dir+"/") || strings.HasPrefix(dir, lit+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relativeTo returns name relative to root, or "" if name is root itself.
+func relativeTo(root, name string) string {
+	if name == root {
+		return ""
+	}
+
+	if root == "." {
+		return name
+	}
+
+	return strings.TrimPrefix(name, root+"/")
+}
+
+// WalkIgnore walks fsys below root like fs.WalkDir, honoring the .gitignore
+// file of every directory it enters, and calls fn for each path with whether
+// it is ignored. Patterns are compiled with default Options and, as in Git,
+// those of a deeper .gitignore are relative to its directory and take
+// precedence over shallower ones. Each .gitignore is read only when its
+// directory is entered: ignored directories are reported and then pruned, so
+// files inside them, including their .gitignore, are never read. '.git'
+// directories are skipped. Errors from fsys and from fn, including
+// fs.SkipDir and fs.SkipAll, are handled as by fs.WalkDir.
+func WalkIgnore(fsys fs.FS, root string, fn func(path string, d fs.DirEntry, ignored bool) error) error {
+	var levels []ignoreLevel
+
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := relativeTo(root, name)
+
+		// Leave the levels of directories the walk has moved out of.
+		for len(levels) > 0 && !levels[len(levels)-1].contains(rel) {
+			levels = levels[:len(levels)-1]
+		}
+
+		ignored := false
+
+		if rel != "" {
+			if d.IsDir() && path.Base(rel) == ".git" {
+				return fs.SkipDir
+			}
+
+			ignored = nestedIgnored(levels, rel, d.IsDir())
+		}
+
+		if err := fn(name, d, ignored); err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if ignored {
+			return fs.SkipDir
+		}
+
+		g, err := NewReadDir(Options{}, fsys, name)
+		if err != nil {
+			return err
+		}
+
+		if len(g.patterns) > 0 {
+			levels = append(levels, ignoreLevel{dir: rel, g: g})
+		}
+
+		return nil
+	})
+}
+
+// ignoreLevel is the matcher of one .gitignore file during WalkIgnore.
+type ignoreLevel struct {
+	// the directory holding the file, relative to the walk root ("" for the root)
+	dir string
+	// the compiled patterns of the file
+	g *GitIgnore
+}
+
+// contains reports whether rel lies below the level's directory.
+func (l ignoreLevel) contains(rel string) bool {
+	return l.dir == "" || strings.HasPrefix(rel, l.dir+"/")
+}
+
+// nestedIgnored decides rel against the levels of its ancestors, deepest
+// first: the first level with a matching pattern decides. Ancestors need no
+// checking, since WalkIgnore never enters ignored directories.
+func nestedIgnored(levels []ignoreLevel, rel string, isDir bool) bool {
+	for i := len(levels) - 1; i >= 0; i-- {
+		sub := rel
+		if levels[i].dir != "" {
+			sub = rel[len(levels[i].dir)+1:]
+		}
+
+		if m := levels[i].g.MatchDirect(sub, isDir); m.Reason != ReasonNoMatch {
+			return m.Ignored
+		}
+	}
+
+	return false
+}
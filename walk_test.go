@@ -0,0 +1,267 @@
+package gitignore_test
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gitignore "github.com/idelchi/go-gitignore"
+)
+
+// testFS is a small in-memory tree shared by the filesystem-backed tests.
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"app.log":             {Data: []byte("x")},
+		"src/main.go":         {Data: []byte("x")},
+		"src/debug.log":       {Data: []byte("x")},
+		"build/out.bin":       {Data: []byte("x")},
+		"docs/readme.md":      {Data: []byte("x")},
+		".git/objects/a.log":  {Data: []byte("x")},
+		"vendor/lib/file.go":  {Data: []byte("x")},
+		"vendor/lib/keep.txt": {Data: []byte("x")},
+	}
+}
+
+func TestPatternReachesAnything(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "*.tmp", "!docs/", "/vendor/lib/keep.txt", "objects/")
+
+	tests := []struct {
+		index int
+		want  bool
+	}{
+		{index: 0, want: true},  // *.log matches app.log
+		{index: 1, want: true},  // build/ matches build
+		{index: 2, want: false}, // no *.tmp files exist
+		{index: 3, want: true},  // docs/ is re-included (deciding negation)
+		{index: 4, want: true},  // rooted literal
+		{index: 5, want: false}, // only exists inside .git, which is skipped
+	}
+
+	for _, tc := range tests {
+		got, err := g.PatternReachesAnything(tc.index, testFS(), ".")
+		if err != nil {
+			t.Fatalf("pattern %d: unexpected error: %v", tc.index, err)
+		}
+
+		if got != tc.want {
+			t.Errorf("pattern %d (%q): got %v, want %v", tc.index, g.Patterns()[tc.index], got, tc.want)
+		}
+	}
+
+	if _, err := g.PatternReachesAnything(len(g.Patterns()), testFS(), "."); err == nil {
+		t.Error("expected error for out-of-range index")
+	}
+}
+
+func TestShouldSkipDir(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("build/", "vendor/**", "!vendor/lib/", "logs/*", "!logs/keep.log")
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{dir: "build", want: true},
+		{dir: "src/build", want: true},
+		{dir: "vendor", want: false},      // only its contents are excluded
+		{dir: "vendor/other", want: true}, // excluded by vendor/**
+		{dir: "vendor/lib", want: false},  // re-included
+		{dir: "logs", want: false},        // logs/keep.log can be re-included
+		{dir: "src", want: false},
+		{dir: ".", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := g.ShouldSkipDir(tc.dir); got != tc.want {
+			t.Errorf("ShouldSkipDir(%q) = %v, want %v", tc.dir, got, tc.want)
+		}
+
+		if got := g.DirMayContainIncluded(tc.dir); got == tc.want {
+			t.Errorf("DirMayContainIncluded(%q) = %v, want %v", tc.dir, got, !tc.want)
+		}
+	}
+
+	// The rescue check sees dir after PathTransform, as Ignored does.
+	opts := gitignore.Options{
+		AllowReincludeUnderExcluded: true,
+		PathTransform:               func(p string) string { return strings.ReplaceAll(p, "\\", "/") },
+	}
+
+	g = gitignore.NewOptions(opts, "build/", "!build/gen/keep.txt")
+
+	for _, dir := range []string{"build/gen", "build\\gen", "build\\gen\\"} {
+		if g.ShouldSkipDir(dir) || !g.DirMayContainIncluded(dir) {
+			t.Errorf("%q: build/gen/keep.txt can be re-included, but the directory would be pruned", dir)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	g := gitignore.New("*.log", "build/", "vendor/**", "!vendor/lib/", "!vendor/lib/keep.txt", "vendor/lib/file.go")
+
+	var visited []string
+
+	pruned := map[string]string{}
+
+	opts := gitignore.WalkOptions{
+		OnPrune: func(path string, by gitignore.Match) { pruned[path] = by.Pattern },
+	}
+
+	err := g.Walk(testFS(), ".", opts, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		visited = append(visited, path)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+
+	want := []string{".", "docs", "docs/readme.md", "src", "src/main.go", "vendor", "vendor/lib", "vendor/lib/keep.txt"}
+	if !slices.Equal(visited, want) {
+		t.Errorf("visited %q, want %q", visited, want)
+	}
+
+	if len(pruned) != 1 || pruned["build"] != "build/" {
+		t.Errorf("pruned %v, want only build by build/", pruned)
+	}
+}
+
+func TestClassifyFS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		g           *gitignore.GitIgnore
+		root        string
+		wantIgnored []string
+		wantKept    []string
+	}{
+		{
+			name:        "pruning",
+			g:           gitignore.New("*.log", "build/", "vendor/**", "!vendor/lib/", "!vendor/lib/keep.txt", "vendor/lib/file.go"),
+			root:        ".",
+			wantIgnored: []string{"app.log", "build", "src/debug.log", "vendor/lib/file.go"},
+			wantKept:    []string{"docs", "docs/readme.md", "src", "src/main.go", "vendor", "vendor/lib", "vendor/lib/keep.txt"},
+		},
+		{
+			name: "re-include under an excluded directory",
+			g: gitignore.NewOptions(
+				gitignore.Options{AllowReincludeUnderExcluded: true}, "vendor/", "!vendor/lib/keep.txt", "docs", "src",
+			),
+			root:        ".",
+			wantIgnored: []string{"docs", "src", "vendor", "vendor/lib", "vendor/lib/file.go"},
+			wantKept:    []string{"app.log", "build", "build/out.bin", "vendor/lib/keep.txt"},
+		},
+		{
+			name:        "subtree",
+			g:           gitignore.New("file.go"),
+			root:        "vendor",
+			wantIgnored: []string{"vendor/lib/file.go"},
+			wantKept:    []string{"vendor/lib", "vendor/lib/keep.txt"},
+		},
+	}
+
+	for _, tc := range tests {
+		ignored, kept, err := tc.g.ClassifyFS(testFS(), tc.root)
+		if err != nil {
+			t.Fatalf("%s: ClassifyFS: %v", tc.name, err)
+		}
+
+		if !slices.Equal(ignored, tc.wantIgnored) {
+			t.Errorf("%s: ignored %q, want %q", tc.name, ignored, tc.wantIgnored)
+		}
+
+		if !slices.Equal(kept, tc.wantKept) {
+			t.Errorf("%s: kept %q, want %q", tc.name, kept, tc.wantKept)
+		}
+	}
+
+	if _, _, err := gitignore.New().ClassifyFS(testFS(), "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ClassifyFS of a missing root: got %v, want fs.ErrNotExist", err)
+	}
+}
+
+// openRecorder is an fs.FS that records every name opened.
+type openRecorder struct {
+	fs.FS
+
+	opened []string
+}
+
+func (r *openRecorder) Open(name string) (fs.File, error) {
+	r.opened = append(r.opened, name)
+
+	return r.FS.Open(name)
+}
+
+func TestWalkIgnore(t *testing.T) {
+	t.Parallel()
+
+	fsys := &openRecorder{FS: fstest.MapFS{
+		".gitignore":        {Data: []byte("*.log\nbuild/\n")},
+		"app.log":           {Data: []byte("x")},
+		"x.tmp":             {Data: []byte("x")},
+		"sub/.gitignore":    {Data: []byte("!keep.log\n*.tmp\n/local/\n")},
+		"sub/keep.log":      {Data: []byte("x")},
+		"sub/a.log":         {Data: []byte("x")},
+		"sub/x.tmp":         {Data: []byte("x")},
+		"sub/deep/keep.log": {Data: []byte("x")},
+		"sub/local/a.txt":   {Data: []byte("x")},
+		"local/a.txt":       {Data: []byte("x")},
+		"build/.gitignore":  {Data: []byte("!*\n")},
+		"build/out.bin":     {Data: []byte("x")},
+		".git/config":       {Data: []byte("x")},
+	}}
+
+	got := map[string]bool{}
+
+	err := gitignore.WalkIgnore(fsys, ".", func(name string, _ fs.DirEntry, ignored bool) error {
+		got[name] = ignored
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkIgnore() error: %v", err)
+	}
+
+	want := map[string]bool{
+		".":                 false,
+		".gitignore":        false,
+		"app.log":           true,
+		"x.tmp":             false,
+		"sub":               false,
+		"sub/.gitignore":    false,
+		"sub/keep.log":      false, // the deeper negation wins
+		"sub/a.log":         true,
+		"sub/x.tmp":         true,
+		"sub/deep":          false,
+		"sub/deep/keep.log": false,
+		"sub/local":         true, // anchored to sub
+		"local":             false,
+		"local/a.txt":       false,
+		"build":             true,
+	}
+
+	if !maps.Equal(got, want) {
+		t.Errorf("WalkIgnore() visited\n%v\nwant\n%v", got, want)
+	}
+
+	for _, name := range fsys.opened {
+		if strings.HasPrefix(name, "build/") || strings.HasPrefix(name, "sub/local/") {
+			t.Errorf("WalkIgnore() opened %q inside a pruned directory", name)
+		}
+	}
+}
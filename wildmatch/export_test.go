@@ -0,0 +1,14 @@
+package wildmatch
+
+import "math"
+
+// MatchNoMemo is MatchOpt, or MatchPrefix when prefix is set, with
+// memoization disabled: the call counter starts too low to ever pass
+// memoThreshold. Tests use it as the reference for memoized results.
+func MatchNoMemo(pattern, text string, opt WMOptions, prefix bool) bool {
+	m := newMatcher(pattern, text, opt)
+	m.prefix = prefix
+	m.calls = math.MinInt
+
+	return m.run() == wmMatch
+}
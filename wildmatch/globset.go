@@ -0,0 +1,144 @@
+package wildmatch
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// GlobSet is an ordered set of patterns matched against a single text at a
+// time. Patterns are classified once when the set is built, so literal and
+// "*literal" patterns are compared without running the full matcher, and
+// matching does not allocate.
+type GlobSet struct {
+	// the classified patterns in input order
+	globs []glob
+}
+
+// globKind selects how a pattern in a GlobSet is compared.
+type globKind uint8
+
+const (
+	// globWild patterns run through the full matcher.
+	globWild globKind = iota
+	// globLiteral patterns contain no metacharacters and compare whole.
+	globLiteral
+	// globSuffix patterns are '*' followed by a literal.
+	globSuffix
+)
+
+// glob is a pattern of a GlobSet with its precomputed kind.
+type glob struct {
+	// the pattern as given
+	pattern string
+	// how the pattern is compared
+	kind globKind
+}
+
+// NewGlobSet classifies patterns into a GlobSet, keeping their order.
+func NewGlobSet(patterns ...string) *GlobSet {
+	s := &GlobSet{globs: make([]glob, len(patterns))}
+
+	for i, p := range patterns {
+		kind := globWild
+
+		switch {
+		case !hasGlobSpecial(p):
+			kind = globLiteral
+		case len(p) > 1 && p[0] == '*' && !hasGlobSpecial(p[1:]):
+			kind = globSuffix
+		}
+
+		s.globs[i] = glob{pattern: p, kind: kind}
+	}
+
+	return s
+}
+
+// Len returns the number of patterns in the set.
+func (s *GlobSet) Len() int {
+	return len(s.globs)
+}
+
+// LastMatch returns the index of the last pattern matching text under opt,
+// mirroring last-match-wins semantics, and false if none matches.
+func (s *GlobSet) LastMatch(text string, opt WMOptions) (int, bool) {
+	for i := len(s.globs) - 1; i >= 0; i-- {
+		if s.globs[i].match(text, opt) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+// MatchEach calls fn, in order, with the index of each pattern that text
+// matches under opt, stopping as soon as fn returns false. Patterns are taken
+// as bytes, as read from a file, and matched without copying, so nothing is
+// allocated; they must not be modified, by fn or otherwise, during the call.
+// This makes it the primitive for callback-driven set matching.
+func MatchEach(patterns [][]byte, text string, opt WMOptions, fn func(i int) bool) {
+	for i, p := range patterns {
+		// The matcher never retains the pattern, so a view of p avoids a copy.
+		pattern := unsafe.String(unsafe.SliceData(p), len(p)) //nolint:gosec	// p outlives the match and is not modified
+
+		if MatchOpt(pattern, text, opt) && !fn(i) {
+			return
+		}
+	}
+}
+
+// match reports whether text matches the glob under opt, agreeing with MatchOpt.
+func (g *glob) match(text string, opt WMOptions) bool {
+	// A per-byte mask is only honored by the full matcher.
+	if opt.CaseFoldMask != nil {
+		return MatchOpt(g.pattern, text, opt)
+	}
+
+	switch g.kind {
+	case globLiteral:
+		return equalFold(text, g.pattern, opt.CaseFold)
+	case globSuffix:
+		suffix := g.pattern[1:]
+		if len(text) < len(suffix) {
+			return false
+		}
+
+		head := text[:len(text)-len(suffix)]
+
+		// In pathname mode the leading '*' cannot match '/'.
+		if opt.Pathname && strings.IndexByte(head, '/') >= 0 {
+			return false
+		}
+
+		return equalFold(text[len(head):], suffix, opt.CaseFold)
+	default:
+		return MatchOpt(g.pattern, text, opt)
+	}
+}
+
+// hasGlobSpecial reports whether s contains any glob metacharacter.
+func hasGlobSpecial(s string) bool {
+	for i := range len(s) {
+		if isGlobSpecial(s[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equalFold reports whether a and b are equal, comparing ASCII letters
+// case-insensitively when fold is set, as literal pattern bytes are.
+func equalFold(a, b string, fold bool) bool {
+	if !fold || len(a) != len(b) {
+		return a == b
+	}
+
+	for i := range len(a) {
+		if asciiToLower(a[i]) != asciiToLower(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
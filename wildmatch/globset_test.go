@@ -0,0 +1,98 @@
+package wildmatch_test
+
+import (
+	"slices"
+	"testing"
+
+	wildmatch "github.com/idelchi/go-gitignore/wildmatch"
+)
+
+func TestGlobSetLastMatch(t *testing.T) {
+	t.Parallel()
+
+	patterns := []string{"*.go", "main.go", "*_test.go", "cmd/*", "[A-Z]*", "*/x.go", "*.GO", "**/*.md"}
+	set := wildmatch.NewGlobSet(patterns...)
+
+	texts := []string{
+		"main.go", "a_test.go", "cmd/main.go", "cmd/x.go", "README.md", "docs/README.md", "Main.GO",
+		"MAIN.GO", "x.go", "a/b.go", "", "go",
+	}
+
+	options := []wildmatch.WMOptions{
+		{},
+		{Pathname: true},
+		{CaseFold: true},
+		{Pathname: true, CaseFold: true},
+	}
+
+	for _, opt := range options {
+		for _, text := range texts {
+			want := -1
+
+			for i := len(patterns) - 1; i >= 0; i-- {
+				if wildmatch.MatchOpt(patterns[i], text, opt) {
+					want = i
+
+					break
+				}
+			}
+
+			got, ok := set.LastMatch(text, opt)
+			if got != want || ok != (want >= 0) {
+				t.Errorf("LastMatch(%q, %+v) = (%d, %v), want %d", text, opt, got, ok, want)
+			}
+		}
+	}
+
+	if set.Len() != len(patterns) {
+		t.Errorf("Len() = %d, want %d", set.Len(), len(patterns))
+	}
+}
+
+func TestGlobSetAllocs(t *testing.T) {
+	set := wildmatch.NewGlobSet("*.log", "build", "[a-z]*.tmp", "**/cache/**")
+	opt := wildmatch.WMOptions{Pathname: true, CaseFold: true}
+
+	if n := testing.AllocsPerRun(100, func() { set.LastMatch("src/App.LOG", opt) }); n != 0 {
+		t.Errorf("LastMatch allocated %v times per run, want 0", n)
+	}
+}
+
+func TestMatchEach(t *testing.T) {
+	patterns := [][]byte{[]byte("*.go"), []byte("main.go"), []byte("*.md"), []byte("[mn]*"), []byte("*")}
+	opt := wildmatch.WMOptions{Pathname: true}
+
+	tests := []struct {
+		text  string
+		limit int // stop after this many matches
+		want  []int
+	}{
+		{text: "main.go", limit: 10, want: []int{0, 1, 3, 4}},
+		{text: "main.go", limit: 2, want: []int{0, 1}},
+		{text: "main.go", limit: 1, want: []int{0}},
+		{text: "README.md", limit: 10, want: []int{2, 4}},
+		{text: "cmd/main.go", limit: 10, want: nil},
+	}
+
+	for _, tc := range tests {
+		var got []int
+
+		wildmatch.MatchEach(patterns, tc.text, opt, func(i int) bool {
+			got = append(got, i)
+
+			return len(got) < tc.limit
+		})
+
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("MatchEach(%q) with limit %d visited %v, want %v", tc.text, tc.limit, got, tc.want)
+		}
+	}
+
+	var n int
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		wildmatch.MatchEach(patterns, "main.go", opt, func(int) bool { n++; return true })
+	}); allocs != 0 {
+		t.Errorf("MatchEach allocated %v times per run, want 0", allocs)
+	}
+}
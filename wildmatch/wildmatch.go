@@ -1,6 +1,12 @@
 // Package wildmatch implements Git's wildmatch.c semantics in Go.
 package wildmatch
 
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
 // Internal result codes.
 const (
 	// successful match.
@@ -19,6 +25,8 @@ const (
 	wmCaseFold = 1 << iota
 	// enable directory (slash) sensitive matching.
 	wmPathname
+	// let character classes match '/' even with wmPathname.
+	wmClassSlash
 )
 
 // Match reports whether text matches pattern. If pathname==true, '/' is special
@@ -33,16 +41,70 @@ func Match(pattern, text string, pathname bool) bool {
 	return wildmatch(pattern, text, flags) == wmMatch
 }
 
+// GlobMatch reports whether text matches the glob pattern with no separator
+// semantics: every byte, including '/' and '.', is ordinary, so '*' and '?'
+// match any bytes. It suits non-path strings such as hostnames, where
+// "*.example.com" matches both "www.example.com" and "a.b.example.com".
+// Classes, escapes and POSIX names behave as in Match.
+func GlobMatch(pattern, text string) bool {
+	return wildmatch(pattern, text, 0) == wmMatch
+}
+
+// GlobMatchFold is like GlobMatch but compares ASCII letters case-insensitively.
+func GlobMatchFold(pattern, text string) bool {
+	return wildmatch(pattern, text, wmCaseFold) == wmMatch
+}
+
 // WMOptions are options for MatchOpt.
 type WMOptions struct {
 	// Pathname: treat '/' as a directory separator with special handling.
 	Pathname bool
 	// CaseFold: enable ASCII-only case-insensitive matching.
 	CaseFold bool
+	// ClassMatchesSeparator: let a character class match '/' in Pathname mode.
+	// Git never does; this is for matching arbitrary strings.
+	ClassMatchesSeparator bool
+	// CaseFoldMask: when non-nil, CaseFoldMask[i] reports whether text byte i is
+	// compared case-insensitively, overriding CaseFold. Missing entries do not fold.
+	CaseFoldMask []bool
+	// ExtraClasses registers additional named classes usable as '[[:name:]]'.
+	// Built-in POSIX names take precedence; unknown names still abort the match,
+	// as in Git. The predicate receives the unfolded text byte.
+	ExtraClasses map[string]func(byte) bool
+	// MaxStarstar, when positive, bounds how many '**' can be active at once,
+	// that is how deeply the rest of a pattern may be matched after one '**'
+	// inside another, which is where adversarial patterns spend their time.
+	// A match needing more fails. Without Pathname every '*' counts, as it
+	// matches like '**'. Zero means no limit.
+	MaxStarstar int
+	// Trace, when non-nil, receives a line for every branch the matcher takes:
+	// recursion, star expansion, class results, mismatches and aborts. It is
+	// meant for investigating parity with Git; the wording is not stable.
+	// Matching without it pays only a nil check per branch.
+	Trace func(event string)
 }
 
 // MatchOpt matches text against pattern with explicit options.
 func MatchOpt(pattern, text string, opt WMOptions) bool {
+	m := newMatcher(pattern, text, opt)
+
+	return m.run() == wmMatch
+}
+
+// MatchPrefix reports whether some text starting with prefix, including prefix
+// itself, could match pattern under opt. For "src/**/x" it holds for the
+// prefix "src/a/b" but not for "lib/a", which makes it the primitive for
+// pruning directories no pattern can reach. Patterns that Git aborts on (see
+// Check) never match any extension.
+func MatchPrefix(pattern, prefix string, opt WMOptions) bool {
+	m := newMatcher(pattern, prefix, opt)
+	m.prefix = true
+
+	return m.run() == wmMatch
+}
+
+// newMatcher prepares a matcher for text against pattern with opt.
+func newMatcher(pattern, text string, opt WMOptions) matcher {
 	flags := 0
 
 	if opt.Pathname {
@@ -53,13 +115,321 @@ func MatchOpt(pattern, text string, opt WMOptions) bool {
 		flags |= wmCaseFold
 	}
 
-	return wildmatch(pattern, text, flags) == wmMatch
+	if opt.ClassMatchesSeparator {
+		flags |= wmClassSlash
+	}
+
+	return matcher{
+		pattern:      pattern,
+		text:         text,
+		flags:        flags,
+		foldMask:     opt.CaseFoldMask,
+		extraClasses: opt.ExtraClasses,
+		maxStarstar:  opt.MaxStarstar,
+		trace:        opt.Trace,
+	}
 }
 
-// wildmatch is a small shim that converts Go strings to byte slices and launches
-// the core matching routine, preserving the internal return codes for fidelity.
+// wildmatch is a small shim that launches the core matching routine,
+// preserving the internal return codes for fidelity.
 func wildmatch(pattern, text string, wmFlags int) int {
-	return dowild([]byte(pattern), []byte(text), 0, 0, wmFlags)
+	m := matcher{pattern: pattern, text: text, flags: wmFlags}
+
+	return m.run()
+}
+
+// matcher holds the state shared by all recursive dowild calls for one match.
+type matcher struct {
+	// the pattern being matched
+	pattern string
+	// the text being matched against
+	text string
+	// wm* flags applying to the whole text
+	flags int
+	// optional per-byte override of wmCaseFold for text positions
+	foldMask []bool
+	// caller-registered named classes
+	extraClasses map[string]func(byte) bool
+	// whether text is only a prefix of the text to match (see MatchPrefix)
+	prefix bool
+	// number of recursive dowild calls made so far
+	calls int
+	// number of recursive dowild calls currently on the stack
+	depth int
+	// the limit on active '**' (see WMOptions.MaxStarstar), or 0
+	maxStarstar int
+	// number of '**' whose recursion is currently on the stack
+	starstars int
+	// memoized results per (pi, ti) state, allocated once backtracking exceeds memoThreshold
+	memo []int8
+	// the pooled buffer backing memo, if any
+	pooled *[]int8
+	// optional receiver of trace events (see WMOptions.Trace)
+	trace func(event string)
+}
+
+// event formats a trace event. Callers check m.trace first, so that building
+// the arguments costs nothing when tracing is off.
+func (m *matcher) event(format string, args ...any) {
+	m.trace(fmt.Sprintf(format, args...))
+}
+
+// resultName names an internal result code for trace events.
+func resultName(result int) string {
+	switch result {
+	case wmMatch:
+		return "match"
+	case wmNoMatch:
+		return "no match"
+	case wmAbortToStarstar:
+		return "abort to '**'"
+	default:
+		return "abort"
+	}
+}
+
+// run matches the whole text against the whole pattern and then returns any
+// pooled scratch memory.
+func (m *matcher) run() int {
+	if m.trace != nil {
+		m.event("match pattern %q against text %q", m.pattern, m.text)
+	}
+
+	result := m.dowild(0, 0)
+
+	if m.trace != nil {
+		m.event("result: %s", resultName(result))
+	}
+
+	if m.pooled != nil {
+		*m.pooled = m.memo
+		memoPool.Put(m.pooled)
+		m.pooled = nil
+	}
+
+	return result
+}
+
+// maxPooledMemo is the largest memo table, in states, recycled through
+// memoPool; larger ones, which only pathological inputs need, are left to
+// the garbage collector.
+const maxPooledMemo = 1 << 16
+
+// memoPool recycles memo tables between matches.
+//
+//nolint:gochecknoglobals	// shared scratch pool
+var memoPool = sync.Pool{New: func() any { return new([]int8) }}
+
+// allocMemo sets memo to a zeroed table of n states, reusing pooled memory
+// when the table is small enough.
+func (m *matcher) allocMemo(n int) {
+	if n > maxPooledMemo {
+		m.memo = make([]int8, n)
+
+		return
+	}
+
+	buf := memoPool.Get().(*[]int8) //nolint:forcetypeassert	// only *[]int8 is pooled
+	if cap(*buf) < n {
+		*buf = make([]int8, n)
+	} else {
+		*buf = (*buf)[:n]
+		clear(*buf)
+	}
+
+	m.memo, m.pooled = *buf, buf
+}
+
+// memoThreshold is the number of recursive calls after which results are memoized.
+// Typical patterns never reach it and stay allocation-free.
+const memoThreshold = 64
+
+// maxDepth bounds the nesting of recursive dowild calls, each of which starts
+// at a later '*' of the pattern. Patterns nesting deeper, which only
+// adversarial input does, abort like malformed patterns instead of growing
+// the stack without bound.
+const maxDepth = 1 << 12
+
+// recurse evaluates dowild from (pi, ti). Since the outcome depends only on the
+// state (pi, ti), results are memoized once backtracking becomes expensive,
+// bounding the total work to O(len(pattern)·len(text)) states.
+func (m *matcher) recurse(pi, ti int) int {
+	if m.depth >= maxDepth {
+		if m.trace != nil {
+			m.event("depth limit %d reached: abort", maxDepth)
+		}
+
+		return wmAbortAll
+	}
+
+	if m.trace != nil {
+		m.event("recurse: pattern %q against text %q", m.pattern[pi:], m.text[ti:])
+	}
+
+	m.depth++
+	defer func() { m.depth-- }()
+
+	m.calls++
+
+	if m.memo == nil {
+		if m.calls <= memoThreshold {
+			return m.dowild(pi, ti)
+		}
+
+		m.allocMemo((len(m.pattern) + 1) * (len(m.text) + 1))
+	}
+
+	key := pi*(len(m.text)+1) + ti
+
+	// Stored values are offset so that the zero value means "not computed".
+	const memoOffset = 3
+
+	if v := m.memo[key]; v != 0 {
+		if m.trace != nil {
+			m.event("memoized: %s", resultName(int(v)-memoOffset))
+		}
+
+		return int(v) - memoOffset
+	}
+
+	result := m.dowild(pi, ti)
+
+	m.memo[key] = int8(result + memoOffset)
+
+	return result
+}
+
+// recurseStarstar is recurse for the rest of the pattern after a '**', which
+// stays active until it returns; beyond maxStarstar active ones the whole
+// match aborts.
+func (m *matcher) recurseStarstar(pi, ti int) int {
+	if m.maxStarstar > 0 && m.starstars >= m.maxStarstar {
+		if m.trace != nil {
+			m.event("'**' limit %d reached: abort", m.maxStarstar)
+		}
+
+		return wmAbortAll
+	}
+
+	m.starstars++
+	defer func() { m.starstars-- }()
+
+	return m.recurse(pi, ti)
+}
+
+// exhausted returns the result when the text runs out before the pattern at
+// pi does: a match in prefix mode if the rest of the pattern is well-formed,
+// since the text can then be extended to match it, and an abort otherwise.
+func (m *matcher) exhausted(pi int) int {
+	result := wmAbortAll
+	if m.prefix && check(m.pattern[pi:], m.extraClasses) == nil {
+		result = wmMatch
+	}
+
+	if m.trace != nil {
+		m.event("text exhausted before pattern %q: %s", m.pattern[pi:], resultName(result))
+	}
+
+	return result
+}
+
+// flagsAt returns the effective flags for comparing the text byte at ti.
+func (m *matcher) flagsAt(ti int) int {
+	if m.foldMask == nil {
+		return m.flags
+	}
+
+	if ti < len(m.foldMask) && m.foldMask[ti] {
+		return m.flags | wmCaseFold
+	}
+
+	return m.flags &^ wmCaseFold
+}
+
+// Bits of classTable, one per POSIX class name.
+const (
+	classAlnum uint16 = 1 << iota
+	classAlpha
+	classBlank
+	classCntrl
+	classDigit
+	classGraph
+	classLower
+	classPrint
+	classPunct
+	classSpace
+	classUpper
+	classXDigit
+)
+
+// classTable holds, for every byte, the bits of the POSIX classes it belongs
+// to, so class matching is a single lookup. It is derived from the asciiIs*
+// predicates, which remain the definition of each class.
+//
+//nolint:gochecknoglobals	// read-only lookup table
+var classTable = func() [256]uint16 {
+	var t [256]uint16
+
+	classes := [...]struct {
+		bit uint16
+		is  func(byte) bool
+	}{
+		{classAlnum, asciiIsAlnum},
+		{classAlpha, asciiIsAlpha},
+		{classBlank, asciiIsSpace},
+		{classCntrl, asciiIsCntrl},
+		{classDigit, asciiIsDigit},
+		{classGraph, asciiIsGraph},
+		{classLower, asciiIsLower},
+		{classPrint, asciiIsPrint},
+		{classPunct, asciiIsPunct},
+		{classSpace, isPosixSpace},
+		{classUpper, asciiIsUpper},
+		{classXDigit, asciiIsXDigit},
+	}
+
+	for _, c := range classes {
+		for i := range t {
+			if c.is(byte(i)) {
+				t[i] |= c.bit
+			}
+		}
+	}
+
+	return t
+}()
+
+// posixClassBit returns the classTable bit of a POSIX class name, or 0 if the
+// name is not one.
+func posixClassBit(name string) uint16 {
+	switch name {
+	case "alnum":
+		return classAlnum
+	case "alpha":
+		return classAlpha
+	case "blank":
+		return classBlank
+	case "cntrl":
+		return classCntrl
+	case "digit":
+		return classDigit
+	case "graph":
+		return classGraph
+	case "lower":
+		return classLower
+	case "print":
+		return classPrint
+	case "punct":
+		return classPunct
+	case "space":
+		return classSpace
+	case "upper":
+		return classUpper
+	case "xdigit":
+		return classXDigit
+	default:
+		return 0
+	}
 }
 
 // asciiLowerDelta is the distance between uppercase and lowercase ASCII letters.
@@ -106,6 +476,12 @@ func asciiIsSpace(b byte) bool {
 	return b == ' ' || b == '\t'
 }
 
+// isPosixSpace reports whether b is matched by "[[:space:]]": space, tab,
+// newline, carriage return, form feed or vertical tab.
+func isPosixSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
+
 // asciiIsPrint reports whether b is an ASCII printable character (0x20-0x7E).
 func asciiIsPrint(b byte) bool {
 	return b >= 0x20 && b <= 0x7e
@@ -152,16 +528,22 @@ func isGlobSpecial(c byte) bool {
 	return c == '*' || c == '?' || c == '[' || c == '\\'
 }
 
-// dowild is a port of Git's wildmatch.c main routine.
-func dowild(pattern, text []byte, pi, ti, flags int) int {
+// dowild is a port of Git's wildmatch.c main routine, starting at pattern index pi
+// and text index ti.
+func (m *matcher) dowild(pi, ti int) int {
+	pattern, text := m.pattern, m.text
+
 	var pCh byte
 
 	for pi < len(pattern) {
 		pCh = pattern[pi]
 
+		// Effective flags for the current text position.
+		flags := m.flagsAt(ti)
+
 		// If text is exhausted but pattern isn't (and next is not '*'), abort.
 		if ti >= len(text) && pCh != '*' {
-			return wmAbortAll
+			return m.exhausted(pi)
 		}
 
 		// Prepare comparison byte from text with optional ASCII folding.
@@ -183,9 +565,12 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 				return wmAbortAll
 			}
 
-			next := foldASCII(pattern[pi], flags)
+			// Like Git, the escaped byte is compared unfolded against the folded text.
+			if ti >= len(text) || tCh != pattern[pi] {
+				if m.trace != nil {
+					m.event("escaped %q does not match %q: no match", pattern[pi], text[ti])
+				}
 
-			if ti >= len(text) || tCh != next {
 				return wmNoMatch
 			}
 
@@ -200,6 +585,10 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 			}
 
 			if flags&wmPathname != 0 && text[ti] == '/' {
+				if m.trace != nil {
+					m.event("'?' does not match '/': no match")
+				}
+
 				return wmNoMatch
 			}
 
@@ -234,7 +623,11 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 						(pi+1 < len(pattern) && pattern[pi] == '\\' && pattern[pi+1] == '/')):
 					// Special case from C code: try zero-width match first.
 					if pi < len(pattern) && pattern[pi] == '/' {
-						if dowild(pattern, text, pi+1, ti, flags) == wmMatch {
+						if m.trace != nil {
+							m.event("'**/' tries matching zero directories")
+						}
+
+						if m.recurseStarstar(pi+1, ti) == wmMatch {
 							return wmMatch
 						}
 					}
@@ -249,6 +642,10 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 				matchSlash = flags&wmPathname == 0
 			}
 
+			if m.trace != nil {
+				m.event("star before pattern %q at text %q, matching '/': %t", pattern[pi:], text[ti:], matchSlash)
+			}
+
 			// Handle end-of-pattern after a star or run of stars.
 			if pi >= len(pattern) {
 				// Trailing '*' or '**'.
@@ -256,11 +653,19 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 					// Verify no '/' remains in text when '/' cannot be matched.
 					for i := ti; i < len(text); i++ {
 						if text[i] == '/' {
+							if m.trace != nil {
+								m.event("trailing star cannot match '/' in %q: abort to '**'", text[ti:])
+							}
+
 							return wmAbortToStarstar
 						}
 					}
 				}
 
+				if m.trace != nil {
+					m.event("trailing star matches %q: match", text[ti:])
+				}
+
 				return wmMatch
 			}
 
@@ -272,7 +677,7 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 				}
 
 				if ti >= len(text) {
-					return wmAbortAll
+					return m.exhausted(pi)
 				}
 
 				// The '/' will be consumed by the main loop on the next iteration.
@@ -281,24 +686,39 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 
 			// Fast-forward when the next token is a literal (Git optimization).
 			if pi < len(pattern) && !isGlobSpecial(pattern[pi]) {
-				lit := foldASCII(pattern[pi], flags)
-
 				pos := ti
 
 				for pos < len(text) && (matchSlash || text[pos] != '/') {
-					if foldASCII(text[pos], flags) == lit {
+					posFlags := m.flagsAt(pos)
+
+					if foldASCII(text[pos], posFlags) == foldASCII(pattern[pi], posFlags) {
 						break
 					}
 
 					pos++
 				}
 
+				// In prefix mode the literal may still follow the text.
+				if m.prefix && pos >= len(text) {
+					return m.exhausted(pi)
+				}
+
 				if pos >= len(text) || (!matchSlash && pos < len(text) && text[pos] == '/') {
+					result := wmAbortToStarstar
 					if matchSlash {
-						return wmAbortAll
+						result = wmAbortAll
 					}
 
-					return wmAbortToStarstar
+					if m.trace != nil {
+						m.event("star finds no %q in %q before '/' or the end: %s",
+							pattern[pi], text[ti:], resultName(result))
+					}
+
+					return result
+				}
+
+				if m.trace != nil && pos > ti {
+					m.event("star skips %q to literal %q", text[ti:pos], pattern[pi])
 				}
 
 				ti = pos
@@ -307,20 +727,33 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 			// Main '*' matching loop from Git's C code.
 			for ti < len(text) {
 				// Try to match rest of pattern at current position.
-				result := dowild(pattern, text, pi, ti, flags)
+				var result int
+				if matchSlash {
+					result = m.recurseStarstar(pi, ti)
+				} else {
+					result = m.recurse(pi, ti)
+				}
 
 				if result != wmNoMatch {
 					if !matchSlash || result != wmAbortToStarstar {
+						if m.trace != nil {
+							m.event("star stops at text %q: %s", text[ti:], resultName(result))
+						}
+
 						return result
 					}
 				} else if !matchSlash && text[ti] == '/' {
+					if m.trace != nil {
+						m.event("star cannot cross '/' in %q: abort to '**'", text[ti:])
+					}
+
 					return wmAbortToStarstar
 				}
 
 				ti++
 			}
 
-			return wmAbortAll
+			return m.exhausted(pi)
 
 		case '[':
 			// Character class.
@@ -328,6 +761,8 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 				return wmNoMatch
 			}
 
+			classStart := pi
+
 			pi++
 
 			if pi >= len(pattern) {
@@ -369,9 +804,7 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 
 					pCh = pattern[pi]
 
-					comp := foldASCII(pCh, flags)
-
-					if tCh == comp {
+					if tCh == pCh {
 						matched = true
 					}
 
@@ -392,27 +825,12 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 						endCh = pattern[pi]
 					}
 
-					start := prevCh
-					stop := endCh
-
-					// Apply case-fold to range endpoints for inclusive check.
-					if flags&wmCaseFold != 0 {
-						if asciiIsUpper(start) {
-							start = asciiToLower(start)
-						}
-
-						if asciiIsUpper(stop) {
-							stop = asciiToLower(stop)
-						}
-					}
-
-					tc := tCh
-
-					if tc >= start && tc <= stop {
+					// Range endpoints are not folded; under case folding the
+					// uppercase form of the (lowered) text byte is tried as well.
+					if tCh >= prevCh && tCh <= endCh {
 						matched = true
-					} else if flags&wmCaseFold != 0 && asciiIsLower(text[ti]) {
-						// Uppercase counterpart also in range.
-						tUpper := text[ti] - asciiLowerDelta
+					} else if flags&wmCaseFold != 0 && asciiIsLower(tCh) {
+						tUpper := tCh - asciiLowerDelta
 
 						if tUpper >= prevCh && tUpper <= endCh {
 							matched = true
@@ -438,78 +856,41 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 					// Ensure trailing ':]'
 					if classEndIndex-1 <= startIndex || pattern[classEndIndex-1] != ':' {
 						// Treat like normal set: literal '['.
-						if tCh == foldASCII('[', flags) {
+						if tCh == '[' {
 							matched = true
 						}
 
+						prevCh = '['
+
 						goto nextClassChar
 					}
 
-					name := string(pattern[startIndex : classEndIndex-1])
+					name := pattern[startIndex : classEndIndex-1]
 
-					switch name {
-					case "alnum":
-						if asciiIsAlnum(text[ti]) {
-							matched = true
-						}
-					case "alpha":
-						if asciiIsAlpha(text[ti]) {
-							matched = true
-						}
-					case "blank":
-						if asciiIsSpace(text[ti]) {
-							matched = true
-						}
-					case "cntrl":
-						if asciiIsCntrl(text[ti]) {
-							matched = true
-						}
-					case "digit":
-						if asciiIsDigit(text[ti]) {
+					if bit := posixClassBit(name); bit != 0 {
+						// Under case folding, lowered text still satisfies "upper".
+						if classTable[tCh]&bit != 0 ||
+							(bit == classUpper && flags&wmCaseFold != 0 && classTable[tCh]&classLower != 0) {
 							matched = true
 						}
-					case "graph":
-						if asciiIsGraph(text[ti]) {
-							matched = true
-						}
-					case "lower":
-						if asciiIsLower(text[ti]) {
-							matched = true
-						}
-					case "print":
-						if asciiIsPrint(text[ti]) {
-							matched = true
-						}
-					case "punct":
-						if asciiIsPunct(text[ti]) {
-							matched = true
-						}
-					case "space":
-						if text[ti] == ' ' || text[ti] == '\t' || text[ti] == '\n' || text[ti] == '\r' ||
-							text[ti] == '\f' ||
-							text[ti] == '\v' {
-							matched = true
-						}
-					case "upper":
-						if asciiIsUpper(text[ti]) || (flags&wmCaseFold != 0 && asciiIsLower(text[ti])) {
-							matched = true
+					} else {
+						// Unknown names abort like Git unless registered by the caller.
+						isClass, ok := m.extraClasses[name]
+						if !ok {
+							return wmAbortAll
 						}
-					case "xdigit":
-						if asciiIsXDigit(text[ti]) {
+
+						if isClass(text[ti]) {
 							matched = true
 						}
-					default:
-						return wmAbortAll
 					}
 
 					// Consume up to the closing ']' of class token.
 					pi = classEndIndex
 					prevCh = 0
 				default:
-					// Single literal character inside class.
-					comp := foldASCII(pCh, flags)
-
-					if tCh == comp {
+					// Single literal character inside class, compared unfolded.
+					if tCh == pCh {
 						matched = true
 					}
 
@@ -526,13 +907,21 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 
 			pi++ // Skip closing ']'.
 
+			if m.trace != nil {
+				m.event("class %q matches %q: %t", pattern[classStart:pi], text[ti], matched != negated)
+			}
+
 			// Check match result.
 			if matched == negated {
 				return wmNoMatch
 			}
 
-			// With WM_PATHNAME, a class never matches '/'.
-			if flags&wmPathname != 0 && text[ti] == '/' {
+			// With WM_PATHNAME, a class never matches '/' unless explicitly allowed.
+			if flags&wmPathname != 0 && flags&wmClassSlash == 0 && text[ti] == '/' {
+				if m.trace != nil {
+					m.event("class cannot match '/': no match")
+				}
+
 				return wmNoMatch
 			}
 
@@ -545,6 +934,10 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 			}
 
 			if tCh != foldASCII(pCh, flags) {
+				if m.trace != nil {
+					m.event("literal %q does not match %q: no match", pattern[pi], text[ti])
+				}
+
 				return wmNoMatch
 			}
 
@@ -556,8 +949,148 @@ func dowild(pattern, text []byte, pi, ti, flags int) int {
 
 	// Pattern exhausted — text must also be exhausted to succeed.
 	if ti < len(text) {
+		if m.trace != nil {
+			m.event("pattern exhausted before text %q: no match", text[ti:])
+		}
+
 		return wmNoMatch
 	}
 
 	return wmMatch
 }
+
+// Errors returned by Check for patterns that Git's wildmatch aborts on.
+var (
+	// ErrTrailingBackslash reports a pattern ending in an unescaped '\\'.
+	ErrTrailingBackslash = errors.New("pattern ends with an unescaped backslash")
+	// ErrUnterminatedClass reports a '[' without a closing ']'.
+	ErrUnterminatedClass = errors.New("unterminated character class")
+	// ErrUnknownClass reports a '[:name:]' POSIX class that is not supported.
+	ErrUnknownClass = errors.New("unknown POSIX character class")
+)
+
+// Check reports whether pattern is well-formed. Git's wildmatch silently aborts
+// on malformed patterns, so such patterns never match anything; Check surfaces
+// those cases as one of ErrTrailingBackslash, ErrUnterminatedClass or ErrUnknownClass.
+func Check(pattern string) error {
+	return check(pattern, nil)
+}
+
+// MinLen returns a lower bound on the length in bytes of any text pattern
+// matches, with or without Pathname: each literal byte, escape sequence, '?'
+// and character class consumes one byte of text, while '*' and '**' may
+// consume none, and neither does the '/' of a "**/" component, which can
+// match zero directories ("a/**/b" matches "a/b"). Malformed patterns never
+// match, and their count stops at the malformed class.
+func MinLen(pattern string) int {
+	n := 0
+
+	for pi := 0; pi < len(pattern); pi++ {
+		switch pattern[pi] {
+		case '*':
+			end := pi
+			for end < len(pattern) && pattern[end] == '*' {
+				end++
+			}
+
+			// A "**/" component may match nothing, its '/' included.
+			if end-pi > 1 && (pi == 0 || pattern[pi-1] == '/') && end < len(pattern) && pattern[end] == '/' {
+				end++
+			}
+
+			pi = end - 1
+
+			continue
+		case '\\':
+			pi++
+		case '[':
+			end, err := checkClass(pattern, pi+1, nil)
+			if err != nil {
+				return n
+			}
+
+			pi = end
+		}
+
+		n++
+	}
+
+	return n
+}
+
+// check implements Check, additionally accepting the class names in extra.
+func check(pattern string, extra map[string]func(byte) bool) error {
+	for pi := 0; pi < len(pattern); pi++ {
+		switch pattern[pi] {
+		case '\\':
+			pi++
+
+			if pi >= len(pattern) {
+				return ErrTrailingBackslash
+			}
+		case '[':
+			end, err := checkClass(pattern, pi+1, extra)
+			if err != nil {
+				return err
+			}
+
+			pi = end
+		}
+	}
+
+	return nil
+}
+
+// checkClass validates a character class whose body starts at pi and returns
+// the index of its closing ']'. It mirrors the class parsing in dowild.
+func checkClass(pattern string, pi int, extra map[string]func(byte) bool) (int, error) {
+	if pi < len(pattern) && (pattern[pi] == '!' || pattern[pi] == '^') {
+		pi++
+	}
+
+	// ']' as first character is literal.
+	if pi < len(pattern) && pattern[pi] == ']' {
+		pi++
+	}
+
+	for ; pi < len(pattern) && pattern[pi] != ']'; pi++ {
+		switch {
+		case pattern[pi] == '\\':
+			pi++
+
+			if pi >= len(pattern) {
+				return 0, ErrUnterminatedClass
+			}
+		case pattern[pi] == '[' && pi+1 < len(pattern) && pattern[pi+1] == ':':
+			const posixClassOffset = 2
+
+			start := pi + posixClassOffset
+			end := start
+
+			for end < len(pattern) && pattern[end] != ']' {
+				end++
+			}
+
+			if end >= len(pattern) {
+				return 0, ErrUnterminatedClass
+			}
+
+			// Without a trailing ':]' the '[' is an ordinary member.
+			if end-1 <= start || pattern[end-1] != ':' {
+				continue
+			}
+
+			if name := pattern[start : end-1]; posixClassBit(name) == 0 && extra[name] == nil {
+				return 0, ErrUnknownClass
+			}
+
+			pi = end
+		}
+	}
+
+	if pi >= len(pattern) {
+		return 0, ErrUnterminatedClass
+	}
+
+	return pi, nil
+}
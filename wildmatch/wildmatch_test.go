@@ -0,0 +1,439 @@
+package wildmatch_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	wildmatch "github.com/idelchi/go-gitignore/wildmatch"
+)
+
+func TestExtraClasses(t *testing.T) {
+	t.Parallel()
+
+	extra := map[string]func(byte) bool{
+		"word":  func(b byte) bool { return b == '_' || (b|0x20 >= 'a' && b|0x20 <= 'z') || (b >= '0' && b <= '9') },
+		"ascii": func(b byte) bool { return b < 0x80 },
+		"alpha": func(byte) bool { return false }, // built-ins take precedence
+	}
+
+	tests := []struct {
+		pattern string
+		text    string
+		extra   bool
+		want    bool
+	}{
+		{pattern: "[[:word:]]*", text: "_tmp", extra: true, want: true},
+		{pattern: "[[:word:]]*", text: "-tmp", extra: true, want: false},
+		{pattern: "[[:word:]]*", text: "_tmp", extra: false, want: false},
+		{pattern: "[![:ascii:]]", text: "\xc3", extra: true, want: true},
+		{pattern: "[[:alpha:]]", text: "a", extra: true, want: true},
+		{pattern: "[[:nope:]]", text: "a", extra: true, want: false},
+	}
+
+	for _, tc := range tests {
+		opt := wildmatch.WMOptions{Pathname: true}
+		if tc.extra {
+			opt.ExtraClasses = extra
+		}
+
+		if got := wildmatch.MatchOpt(tc.pattern, tc.text, opt); got != tc.want {
+			t.Errorf("MatchOpt(%q, %q, extra=%v) = %v, want %v", tc.pattern, tc.text, tc.extra, got, tc.want)
+		}
+	}
+
+	// Predicates see the text byte as given, even when case folding is on.
+	caps := map[string]func(byte) bool{"caps": func(b byte) bool { return b >= 'A' && b <= 'Z' }}
+	if !wildmatch.MatchOpt("[[:caps:]]", "Q", wildmatch.WMOptions{CaseFold: true, ExtraClasses: caps}) {
+		t.Error("extra class received a folded byte")
+	}
+}
+
+func TestClassMatchesSeparator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		text    string
+		allow   bool
+		want    bool
+	}{
+		{pattern: "a[/]b", text: "a/b", allow: false, want: false},
+		{pattern: "a[/]b", text: "a/b", allow: true, want: true},
+		{pattern: "a[!x]b", text: "a/b", allow: false, want: false},
+		{pattern: "a[!x]b", text: "a/b", allow: true, want: true},
+		{pattern: "a?b", text: "a/b", allow: true, want: false}, // only classes are affected
+		{pattern: "a*b", text: "a/b", allow: true, want: false},
+	}
+
+	for _, tc := range tests {
+		opt := wildmatch.WMOptions{Pathname: true, ClassMatchesSeparator: tc.allow}
+
+		if got := wildmatch.MatchOpt(tc.pattern, tc.text, opt); got != tc.want {
+			t.Errorf("MatchOpt(%q, %q, allow=%v) = %v, want %v", tc.pattern, tc.text, tc.allow, got, tc.want)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		text    string
+		want    bool
+		fold    bool
+	}{
+		{pattern: "*.example.com", text: "www.example.com", want: true, fold: true},
+		{pattern: "*.example.com", text: "a.b.example.com", want: true, fold: true},
+		{pattern: "*.example.com", text: "example.com", want: false, fold: false},
+		{pattern: "*.example.com", text: "WWW.Example.COM", want: false, fold: true},
+		{pattern: "api-?.internal", text: "api-3.internal", want: true, fold: true},
+		{pattern: "db[0-9].local", text: "db7.local", want: true, fold: true},
+		{pattern: "db[!0-9].local", text: "db7.local", want: false, fold: false},
+		{pattern: "v1/*", text: "v1/users/42", want: true, fold: true}, // '/' is an ordinary byte
+		{pattern: "\\*.txt", text: "*.txt", want: true, fold: true},
+		{pattern: "\\*.txt", text: "a.txt", want: false, fold: false},
+	}
+
+	for _, tc := range tests {
+		if got := wildmatch.GlobMatch(tc.pattern, tc.text); got != tc.want {
+			t.Errorf("GlobMatch(%q, %q) = %v, want %v", tc.pattern, tc.text, got, tc.want)
+		}
+
+		if got := wildmatch.GlobMatchFold(tc.pattern, tc.text); got != tc.fold {
+			t.Errorf("GlobMatchFold(%q, %q) = %v, want %v", tc.pattern, tc.text, got, tc.fold)
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		prefix  string
+		want    bool
+	}{
+		{pattern: "src/**/x", prefix: "src/a/b", want: true},
+		{pattern: "src/**/x", prefix: "lib/a", want: false},
+		{pattern: "src/**/x", prefix: "", want: true},
+		{pattern: "src/**/x", prefix: "sr", want: true},
+		{pattern: "src/**/x", prefix: "src/a/x", want: true},
+		{pattern: "src/*.go", prefix: "src/a", want: true},
+		{pattern: "src/*.go", prefix: "src/a/b", want: false}, // '*' cannot cross '/'
+		{pattern: "*/cache/", prefix: "a/b", want: false},
+		{pattern: "*/cache/", prefix: "a/ca", want: true},
+		{pattern: "a/[bc]/d", prefix: "a/c", want: true},
+		{pattern: "a/[bc]/d", prefix: "a/e", want: false},
+		{pattern: "a/?", prefix: "a/xy", want: false}, // the prefix is longer than any match
+		{pattern: "a/b", prefix: "a/b", want: true},
+		{pattern: "a/b[", prefix: "a/", want: false}, // malformed patterns never match
+		{pattern: "a/\\", prefix: "a", want: false},
+		{pattern: "a/[[:nope:]]", prefix: "a/", want: false},
+	}
+
+	for _, tc := range tests {
+		opt := wildmatch.WMOptions{Pathname: true}
+
+		if got := wildmatch.MatchPrefix(tc.pattern, tc.prefix, opt); got != tc.want {
+			t.Errorf("MatchPrefix(%q, %q) = %v, want %v", tc.pattern, tc.prefix, got, tc.want)
+		}
+
+		// Anything that matches is trivially its own extension.
+		if wildmatch.MatchOpt(tc.pattern, tc.prefix, opt) && !wildmatch.MatchPrefix(tc.pattern, tc.prefix, opt) {
+			t.Errorf("MatchPrefix(%q, %q) = false for a full match", tc.pattern, tc.prefix)
+		}
+	}
+
+	if !wildmatch.MatchPrefix("SRC/**", "src/A", wildmatch.WMOptions{Pathname: true, CaseFold: true}) {
+		t.Error("MatchPrefix ignored CaseFold")
+	}
+
+	word := map[string]func(byte) bool{"word": func(b byte) bool { return b == '_' }}
+	if !wildmatch.MatchPrefix("a/[[:word:]]", "a/", wildmatch.WMOptions{Pathname: true, ExtraClasses: word}) {
+		t.Error("MatchPrefix rejected a registered extra class in the remaining pattern")
+	}
+}
+
+func TestRecursionDepthLimit(t *testing.T) {
+	t.Parallel()
+
+	// Every '*' nests one level deeper before the text runs out.
+	nested := func(n int) (string, string) {
+		return strings.Repeat("*a", n), strings.Repeat("a", n)
+	}
+
+	if pattern, text := nested(100); !wildmatch.Match(pattern, text, true) {
+		t.Error("100 nested stars: want a match")
+	}
+
+	if pattern, text := nested(5000); wildmatch.Match(pattern, text, true) {
+		t.Error("5000 nested stars: want the match to be aborted")
+	}
+}
+
+func BenchmarkMatchClasses(b *testing.B) {
+	patterns := []string{
+		"[[:alpha:]][[:alnum:]_]*[[:digit:]].[[:lower:]][[:lower:]]",
+		"*[[:punct:]][[:xdigit:]][[:xdigit:]]*",
+		"[![:space:][:cntrl:]]*[[:upper:]]",
+		"[a-zA-Z0-9]*[!a-f]",
+	}
+	texts := []string{"build_cache_2024v9.go", "release-ff-final.tar", "Some File NAME", "x9q"}
+
+	for _, fold := range []bool{false, true} {
+		opt := wildmatch.WMOptions{Pathname: true, CaseFold: fold}
+
+		name := "Exact"
+		if fold {
+			name = "CaseFold"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				for _, p := range patterns {
+					for _, text := range texts {
+						wildmatch.MatchOpt(p, text, opt)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPosixClassesAllBytes(t *testing.T) {
+	t.Parallel()
+
+	between := func(b, lo, hi byte) bool { return b >= lo && b <= hi }
+	alpha := func(b byte) bool { return between(b, 'a', 'z') || between(b, 'A', 'Z') }
+	digit := func(b byte) bool { return between(b, '0', '9') }
+	printable := func(b byte) bool { return between(b, 0x20, 0x7e) }
+
+	classes := map[string]func(byte) bool{
+		"alnum":  func(b byte) bool { return alpha(b) || digit(b) },
+		"alpha":  alpha,
+		"blank":  func(b byte) bool { return b == ' ' || b == '\t' },
+		"cntrl":  func(b byte) bool { return b < 0x20 || b == 0x7f },
+		"digit":  digit,
+		"graph":  func(b byte) bool { return printable(b) && b != ' ' },
+		"lower":  func(b byte) bool { return between(b, 'a', 'z') },
+		"print":  printable,
+		"punct":  func(b byte) bool { return printable(b) && b != ' ' && !alpha(b) && !digit(b) },
+		"space":  func(b byte) bool { return strings.IndexByte(" \t\n\r\f\v", b) >= 0 },
+		"upper":  func(b byte) bool { return between(b, 'A', 'Z') },
+		"xdigit": func(b byte) bool { return digit(b) || between(b, 'a', 'f') || between(b, 'A', 'F') },
+	}
+
+	for name, want := range classes {
+		for i := range 256 {
+			b := byte(i)
+			if got := wildmatch.Match("[[:"+name+":]]", string(b), false); got != want(b) {
+				t.Errorf("[[:%s:]] on %#02x = %v, want %v", name, b, got, want(b))
+			}
+		}
+	}
+}
+
+func TestMemoAllocs(t *testing.T) {
+	// Backtracking past the memo threshold needs a memo table, which is pooled.
+	pattern, text := strings.Repeat("*a", 10)+"b", strings.Repeat("a", 30)
+
+	if n := testing.AllocsPerRun(100, func() { wildmatch.Match(pattern, text, true) }); n >= 1 {
+		t.Errorf("Match allocated %v times per run, want the memo table reused", n)
+	}
+}
+
+func TestMemoMatchesUnmemoized(t *testing.T) {
+	t.Parallel()
+
+	pathname := wildmatch.WMOptions{Pathname: true}
+	folded := wildmatch.WMOptions{Pathname: true, CaseFold: true}
+
+	// Each "**/" tries every later component, so these take well over
+	// memoThreshold (64) recursive calls.
+	dirs := strings.Repeat("a/", 12)
+	blocks := strings.Repeat(strings.Repeat("a/", 20)+"b/", 4)
+
+	tests := []struct {
+		pattern string
+		text    string
+		opt     wildmatch.WMOptions
+		prefix  bool
+	}{
+		{pattern: strings.Repeat("**/a/", 4) + "b", text: dirs + "c", opt: pathname},
+		{pattern: strings.Repeat("**/A/", 4) + "b", text: dirs + "c", opt: folded},
+		{pattern: strings.Repeat("**/b/", 4) + "c", text: blocks + "c", opt: pathname},
+		{pattern: strings.Repeat("**/B/", 4) + "c", text: blocks + "C", opt: folded},
+		{pattern: strings.Repeat("**/B/", 4) + "c", text: blocks + "c", opt: pathname},
+		{pattern: strings.Repeat("**/b/", 4) + "c/d", text: blocks + "c", opt: pathname, prefix: true},
+		{pattern: strings.Repeat("**/B/", 4) + "c/d", text: blocks + "C", opt: folded, prefix: true},
+	}
+
+	for _, tc := range tests {
+		calls := 0
+
+		opt := tc.opt
+		opt.Trace = func(event string) {
+			if strings.HasPrefix(event, "recurse:") {
+				calls++
+			}
+		}
+
+		got := wildmatch.MatchOpt(tc.pattern, tc.text, opt)
+		if tc.prefix {
+			got = wildmatch.MatchPrefix(tc.pattern, tc.text, opt)
+		}
+
+		if calls <= 64 {
+			t.Errorf("%q against %q: only %d calls, the memo is never used", tc.pattern, tc.text, calls)
+		}
+
+		if want := wildmatch.MatchNoMemo(tc.pattern, tc.text, tc.opt, tc.prefix); got != want {
+			t.Errorf("%q against %q (prefix %v) = %v, unmemoized %v", tc.pattern, tc.text, tc.prefix, got, want)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		text    string
+		want    bool
+		event   string
+	}{
+		{pattern: "a/**/b*.go", text: "a/b1.go", want: true, event: "'**/' tries matching zero directories"},
+		{pattern: "a/**/b*.go", text: "a/x/y/bz.go", want: true, event: "recurse"},
+		{pattern: "*.go", text: "src/a.go", want: false, event: "star finds no '.'"},
+		{pattern: "*[a]", text: "b/a", want: false, event: "star cannot cross '/'"},
+		{pattern: "file[!a].txt", text: "filea.txt", want: false, event: `class "[!a]" matches 'a': false`},
+		{pattern: "ab", text: "ac", want: false, event: "literal 'b' does not match 'c'"},
+	}
+
+	for _, tc := range tests {
+		var events []string
+
+		opt := wildmatch.WMOptions{Pathname: true, Trace: func(e string) { events = append(events, e) }}
+
+		if got := wildmatch.MatchOpt(tc.pattern, tc.text, opt); got != tc.want {
+			t.Errorf("MatchOpt(%q, %q) = %v with tracing, want %v", tc.pattern, tc.text, got, tc.want)
+		}
+
+		if !slices.ContainsFunc(events, func(e string) bool { return strings.Contains(e, tc.event) }) {
+			t.Errorf("MatchOpt(%q, %q): no event containing %q in %q", tc.pattern, tc.text, tc.event, events)
+		}
+
+		if last := events[len(events)-1]; !strings.HasPrefix(last, "result: ") {
+			t.Errorf("MatchOpt(%q, %q): last event %q, want the result", tc.pattern, tc.text, last)
+		}
+	}
+}
+
+func TestMaxStarstar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		text    string
+		limit   int
+		want    bool
+	}{
+		{pattern: "a/**/b/**/c", text: "a/x/b/y/c", limit: 0, want: true},
+		{pattern: "a/**/b/**/c", text: "a/x/b/y/c", limit: 2, want: true},
+		{pattern: "a/**/b/**/c", text: "a/x/b/y/c", limit: 1, want: false},
+		{pattern: "a/**/b/**/c", text: "a/b/c", limit: 1, want: false}, // zero-width '**/' is active too
+		{pattern: "**/c", text: "a/b/c", limit: 1, want: true},
+		{pattern: "a/*/b", text: "a/x/b", limit: 1, want: true}, // '*' never counts in pathname mode
+	}
+
+	for _, tc := range tests {
+		opt := wildmatch.WMOptions{Pathname: true, MaxStarstar: tc.limit}
+
+		if got := wildmatch.MatchOpt(tc.pattern, tc.text, opt); got != tc.want {
+			t.Errorf("MatchOpt(%q, %q) with MaxStarstar %d = %v, want %v", tc.pattern, tc.text, tc.limit, got, tc.want)
+		}
+	}
+
+	// Without Pathname every '*' matches like '**' and counts.
+	if wildmatch.MatchOpt("*a*b*c", "xaybzc", wildmatch.WMOptions{MaxStarstar: 2}) {
+		t.Error("MatchOpt(*a*b*c) without Pathname: matched beyond MaxStarstar")
+	}
+
+	// Adversarial nesting fails fast instead of exploring every split.
+	pattern := strings.Repeat("**/a/", 40) + "b"
+	text := strings.Repeat("a/", 200) + "c"
+
+	if wildmatch.MatchOpt(pattern, text, wildmatch.WMOptions{Pathname: true, MaxStarstar: 8}) {
+		t.Errorf("MatchOpt(%q) matched", pattern)
+	}
+}
+
+func TestMinLen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{pattern: "", want: 0},
+		{pattern: "*", want: 0},
+		{pattern: "**/a", want: 1},
+		{pattern: "a/**/b", want: 3},
+		{pattern: "a/**/**/b", want: 3},
+		{pattern: "***/a", want: 1},
+		{pattern: "x**/a", want: 3}, // not a "**" component, so the '/' is required
+		{pattern: "a/**", want: 2},
+		{pattern: "a?c", want: 3},
+		{pattern: "*.tar.gz", want: 7},
+		{pattern: `\*x`, want: 2},
+		{pattern: "[abc]*[0-9]", want: 2},
+		{pattern: "[]a]", want: 1},
+		{pattern: "[!]]x", want: 2},
+		{pattern: "[[:alpha:]]_", want: 2},
+		{pattern: "[[:a]b", want: 2}, // "[:a" without ':]' is an ordinary '[' member
+		{pattern: "x[abc", want: 1},  // unterminated, matches nothing
+		{pattern: "x[[:nope:]]", want: 1},
+	}
+
+	for _, tc := range tests {
+		if got := wildmatch.MinLen(tc.pattern); got != tc.want {
+			t.Errorf("MinLen(%q) = %d, want %d", tc.pattern, got, tc.want)
+		}
+	}
+
+	// Every text a pattern matches is at least MinLen bytes long, with and
+	// without Pathname.
+	const alphabet = "ab[]-:!/"
+
+	texts, level := []string{""}, []string{""}
+	for range 4 {
+		var next []string
+
+		for _, s := range level {
+			for i := range len(alphabet) {
+				next = append(next, s+alphabet[i:i+1])
+			}
+		}
+
+		texts, level = append(texts, next...), next
+	}
+
+	patterns := []string{
+		"a*b", "[a-]?", "[!a]*[]]", "[[:a]", "[a-[]", "[]-a]:", `\[*`, "**[!b]", "[[:punct:]]b",
+		"**/a", "a/**/b", "**/**/b", "a/**", "a**/b", "*/a", "[!/]/**/a",
+	}
+
+	for _, pattern := range patterns {
+		minLen := wildmatch.MinLen(pattern)
+
+		for _, text := range texts {
+			for _, pathname := range []bool{false, true} {
+				if len(text) < minLen && wildmatch.Match(pattern, text, pathname) {
+					t.Errorf("Match(%q, %q, pathname=%v) holds below MinLen %d", pattern, text, pathname, minLen)
+				}
+			}
+		}
+	}
+}